@@ -132,8 +132,9 @@ export const Admin = () => {
                   onChange={(e) => setFormData({ ...formData, status: e.target.value })}
                 >
                   <option value="available">Available</option>
-                  <option value="sold">Sold</option>
                   <option value="reserved">Reserved</option>
+                  <option value="withdrawn">Withdrawn</option>
+                  <option value="sold">Sold</option>
                 </select>
               </div>
 
@@ -302,11 +302,11 @@ export const Auctions = () => {
                     value={bidAmount}
                     onChange={(e) => setBidAmount(e.target.value)}
                     placeholder="Enter your bid"
-                    min={(bidModal.current_price || bidModal.start_price) + 1}
+                    min={bidModal.next_min_bid}
                     step="0.01"
                   />
                   <p style={{ fontSize: '0.85rem', color: 'var(--text-secondary)', marginTop: '0.5rem' }}>
-                    Minimum bid: ${((bidModal.current_price || bidModal.start_price) + 0.01)?.toLocaleString()}
+                    Minimum bid: ${bidModal.next_min_bid?.toLocaleString()}
                   </p>
                 </div>
               </div>
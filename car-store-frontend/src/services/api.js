@@ -47,17 +47,83 @@ export const carsAPI = {
   create: (car) => api.post('/cars', car),
   update: (id, car) => api.put(`/cars?id=${id}`, car),
   delete: (id) => api.delete(`/cars?id=${id}`),
+  getArchived: () => api.get('/cars?archived=true'),
+  restore: (id) => api.post(`/cars/${id}/restore`),
+  decodeVin: (vin) => api.get(`/cars/decode-vin?vin=${encodeURIComponent(vin)}`),
+  getPriceHistory: (id) => api.get(`/cars/${id}/price-history`),
+  compare: (ids) => api.get(`/cars/compare?ids=${ids.join(',')}`),
+  getSimilar: (id) => api.get(`/cars/${id}/similar`),
+};
+
+// Recommendations
+export const recommendationsAPI = {
+  getMy: () => api.get('/recommendations/me'),
+};
+
+// Car documents
+export const documentsAPI = {
+  getForCar: (carId) => api.get(`/cars/${carId}/documents`),
+  upload: (carId, file, type, visibility) => {
+    const form = new FormData();
+    form.append('file', file);
+    form.append('type', type);
+    form.append('visibility', visibility);
+    return api.post(`/cars/${carId}/documents`, form);
+  },
+  download: (id) => api.get(`/documents/${id}`, { responseType: 'blob' }),
+  delete: (id) => api.delete(`/documents/${id}`),
+};
+
+// Showrooms
+export const showroomsAPI = {
+  getAll: (city) => api.get('/showrooms', { params: city ? { city } : {} }),
+  getById: (id) => api.get(`/showrooms/${id}`),
+  create: (showroom) => api.post('/admin/showrooms', showroom),
+  update: (id, showroom) => api.put(`/admin/showrooms/${id}`, showroom),
+  transferCar: (carId, showroomId, reason) => api.post(`/cars/${carId}/transfer`, { showroom_id: showroomId, reason }),
+  getTransfers: (carId) => api.get(`/cars/${carId}/transfers`),
+};
+
+// Currencies
+export const currenciesAPI = {
+  getRates: () => api.get('/exchange-rates'),
+  setRate: (currency, kztPerUnit) => api.put(`/admin/exchange-rates/${currency}`, { kzt_per_unit: kztPerUnit }),
+  setPreferred: (currency) => api.put('/users/me/currency', { currency }),
 };
 
 // Auctions
 export const auctionsAPI = {
   getAll: () => api.get('/auctions'),
   getById: (id) => api.get(`/auctions?id=${id}`),
+  // ещё не начавшиеся, starts_in — секунд до начала
+  getUpcoming: () => api.get('/auctions/upcoming'),
   create: (auction) => api.post('/auctions', auction),
   update: (id, auction) => api.put(`/auctions?id=${id}`, auction),
   delete: (id) => api.delete(`/auctions?id=${id}`),
   placeBid: (auctionId, amount) => 
     api.post('/auctions/bid', { auction_id: auctionId, amount }),
+  // участники под псевдонимами «Bidder N», свои ставки помечены mine
+  getBids: (auctionId) => api.get(`/auctions/${auctionId}/bids`),
+  // доступно, пока buy_now_available: аукцион закрывается, создаётся заказ
+  buyNow: (auctionId) => api.post(`/auctions/${auctionId}/buy-now`),
+  // скрытый максимум: система сама ставит минимальным шагом до этой суммы
+  getProxyBid: (auctionId) => api.get(`/auctions/${auctionId}/proxy-bid`),
+  setProxyBid: (auctionId, maxAmount) =>
+    api.put(`/auctions/${auctionId}/proxy-bid`, { max_amount: maxAmount }),
+  cancelProxyBid: (auctionId) => api.delete(`/auctions/${auctionId}/proxy-bid`),
+  // если аукцион закрылся ниже резервной цены, лидер может предложить свою
+  makeOffer: (auctionId, amount) => api.post(`/auctions/${auctionId}/offer`, { amount }),
+  getMyOffers: () => api.get('/auction-offers/my'),
+  // Admin
+  getOffers: (status) => api.get('/admin/auction-offers', { params: status ? { status } : {} }),
+  acceptOffer: (id) => api.post(`/admin/auction-offers/${id}/accept`),
+  rejectOffer: (id) => api.post(`/admin/auction-offers/${id}/reject`),
+  // EventSource не умеет ставить Authorization — параметром идёт
+  // короткоживущий токен только на этот поток, а не основной токен
+  stream: async (auctionId) => {
+    const { data } = await api.post(`/auctions/${auctionId}/stream-token`);
+    return new EventSource(`${API_BASE_URL}/auctions/${auctionId}/stream?stream_token=${encodeURIComponent(data.stream_token)}`);
+  },
 };
 
 // Orders
@@ -66,6 +132,24 @@ export const ordersAPI = {
   getMy: () => api.get('/orders/my'),
 };
 
+// Reservations
+export const reservationsAPI = {
+  reserve: (carId, deposit = 0) => api.post(`/cars/${carId}/reserve`, { deposit }),
+  cancel: (carId) => api.delete(`/cars/${carId}/reserve`),
+  getMy: () => api.get('/reservations/my'),
+};
+
+// Test drives
+export const testDrivesAPI = {
+  getSlots: (carId, date) => api.get(`/cars/${carId}/test-drives`, { params: { date } }),
+  book: (carId, startsAt) => api.post(`/cars/${carId}/test-drives`, { starts_at: startsAt }),
+  reschedule: (id, startsAt) => api.patch(`/test-drives/${id}`, { starts_at: startsAt }),
+  cancel: (id) => api.delete(`/test-drives/${id}`),
+  getMy: () => api.get('/test-drives/my'),
+  // Admin
+  getCalendar: (params) => api.get('/admin/test-drives', { params }),
+};
+
 // Favorites
 export const favoritesAPI = {
   getAll: () => api.get('/favorites'),
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"car-store/internal/config"
+	"car-store/internal/event"
+	"car-store/internal/model"
+	"car-store/internal/repository"
+	"car-store/internal/service"
+)
+
+const cliUsage = `usage:
+  car-store                      start HTTP server
+  car-store import -file cars.csv [-format csv|json] [-dry-run] [-all-or-nothing]
+  car-store export [-out cars.csv] [-format csv|json] [-status s] [-brand b] [-city c]
+                   [-year-from y] [-year-to y] [-price-min p] [-price-max p]`
+
+// runCLI выполняет подкоманду и возвращает код выхода
+func runCLI(args []string) int {
+	switch args[0] {
+	case "import":
+		return runImport(args[1:])
+	case "export":
+		return runExport(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return 2
+	}
+}
+
+func newInventoryService() (*service.InventoryService, func(), error) {
+	db, err := config.ConnectDB()
+	if err != nil {
+		return nil, nil, err
+	}
+	// в CLI подписчиков нет: история цен пишется в БД, события никто не слушает
+	currencies := service.NewCurrencyService(
+		repository.NewExchangeRateRepository(db),
+		repository.NewUserRepository(db),
+	)
+	svc := service.NewInventoryService(repository.NewCarRepository(db), event.NewBus(), currencies)
+	return svc, func() { db.Close() }, nil
+}
+
+func runImport(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	file := fs.String("file", "", "path to CSV or JSON file")
+	format := fs.String("format", "", "csv or json (default: by file extension)")
+	dryRun := fs.Bool("dry-run", false, "validate and roll back instead of committing")
+	allOrNothing := fs.Bool("all-or-nothing", false, "abort the whole import on the first error")
+	fs.Parse(args)
+
+	if *file == "" {
+		fmt.Fprintln(os.Stderr, cliUsage)
+		return 2
+	}
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*file), ".")
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer f.Close()
+
+	svc, closeDB, err := newInventoryService()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer closeDB()
+
+	report, err := svc.ImportCars(f, *format, model.ImportOptions{
+		DryRun:       *dryRun,
+		AllOrNothing: *allOrNothing,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+
+	if report.Invalid > 0 || report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "output file (default: stdout)")
+	format := fs.String("format", "", "csv or json (default: by file extension, else csv)")
+
+	var filter model.CarFilter
+	fs.StringVar(&filter.Status, "status", "", "filter by status")
+	fs.StringVar(&filter.Brand, "brand", "", "filter by brand")
+	fs.StringVar(&filter.City, "city", "", "filter by showroom city")
+	fs.IntVar(&filter.YearFrom, "year-from", 0, "minimum year")
+	fs.IntVar(&filter.YearTo, "year-to", 0, "maximum year")
+	fs.Var(&filter.PriceMin, "price-min", "minimum price")
+	fs.Var(&filter.PriceMax, "price-max", "maximum price")
+	fs.Parse(args)
+
+	if *format == "" {
+		*format = strings.TrimPrefix(filepath.Ext(*out), ".")
+		if *format == "" {
+			*format = "csv"
+		}
+	}
+
+	w := os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer f.Close()
+		w = f
+	}
+
+	svc, closeDB, err := newInventoryService()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer closeDB()
+
+	if err := svc.ExportCars(w, *format, filter); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
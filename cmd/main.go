@@ -3,16 +3,25 @@ package main
 import (
 	"log"
 	"net/http"
+	"os"
 	"time"
 
 	"car-store/internal/config"
+	"car-store/internal/event"
 	"car-store/internal/handler"
 	"car-store/internal/middleware"
+	"car-store/internal/model"
 	"car-store/internal/repository"
 	"car-store/internal/service"
+	"car-store/internal/storage"
 )
 
 func main() {
+	// подкоманды: import / export
+	if len(os.Args) > 1 {
+		os.Exit(runCLI(os.Args[1:]))
+	}
+
 	// --------------------
 	// DB
 	// --------------------
@@ -24,6 +33,9 @@ func main() {
 
 	log.Println("Connected to PostgreSQL")
 
+	cfg := config.Load()
+	bus := event.NewBus()
+
 	// --------------------
 	// REPOSITORIES
 	// --------------------
@@ -34,35 +46,115 @@ func main() {
 	orderRepo := repository.NewOrderRepository(db)
 	favoriteRepo := repository.NewFavoriteRepository(db)
 	tradeInRepo := repository.NewTradeInRepository(db)
+	reservationRepo := repository.NewReservationRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	exchangeRateRepo := repository.NewExchangeRateRepository(db)
+	showroomRepo := repository.NewShowroomRepository(db)
+	documentRepo := repository.NewDocumentRepository(db)
+	testDriveRepo := repository.NewTestDriveRepository(db)
+	auctionOfferRepo := repository.NewAuctionOfferRepository(db)
+
+	// --------------------
+	// STORAGE
+	// --------------------
+	documentStorage, err := storage.NewLocalDisk(cfg.DocumentsDir)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// --------------------
 	// SERVICES
 	// --------------------
-	tradeInService := service.NewTradeInService(tradeInRepo)
-	carService := service.NewCarService(carRepo)
+	currencyService := service.NewCurrencyService(exchangeRateRepo, userRepo)
+	tradeInService := service.NewTradeInService(tradeInRepo, currencyService)
+	showroomService := service.NewShowroomService(showroomRepo, userRepo)
+	carService := service.NewCarService(carRepo, bus, currencyService, showroomService)
+
+	notificationService := service.NewNotificationService(notificationRepo)
 
-	orderService := service.NewOrderService(orderRepo, carRepo)
+	orderService := service.NewOrderService(orderRepo, carRepo, reservationRepo, bus)
+
+	reservationService := service.NewReservationService(
+		reservationRepo,
+		carRepo,
+		notificationService,
+		cfg.ReservationWindow,
+		cfg.ReservationDeposit,
+	)
+
+	testDriveService := service.NewTestDriveService(
+		testDriveRepo,
+		carRepo,
+		reservationRepo,
+		showroomService,
+		notificationService,
+		cfg.TestDriveSlot,
+		cfg.TestDriveHorizon,
+		cfg.Location,
+	)
 
 	auctionService := service.NewAuctionService(
 		auctionRepo,
 		carRepo,
 		bidRepo,
 		orderService,
+		showroomService,
+		notificationService,
+		bus,
+		model.SoftClose{
+			Window:       cfg.AuctionSoftCloseWindow,
+			Extension:    cfg.AuctionSoftCloseExtension,
+			MaxExtension: cfg.AuctionSoftCloseMax,
+		},
+		cfg.BidIncrements,
+		cfg.AuctionBuyNowCutoff,
+		cfg.AuctionMinDuration,
+	)
+
+	auctionOfferService := service.NewAuctionOfferService(
+		auctionOfferRepo,
+		auctionRepo,
+		bidRepo,
+		carRepo,
+		orderService,
+		showroomService,
+		notificationService,
 	)
 
 	authService := service.NewAuthService(userRepo)
-	favoriteService := service.NewFavoriteService(favoriteRepo)
+	favoriteService := service.NewFavoriteService(favoriteRepo, notificationService, currencyService)
+	inventoryService := service.NewInventoryService(carRepo, bus, currencyService)
+	documentService := service.NewDocumentService(documentRepo, carRepo, documentStorage, showroomService)
+	comparisonService := service.NewComparisonService(carRepo, currencyService)
+	recommendationService := service.NewRecommendationService(carRepo, favoriteRepo, orderRepo, tradeInRepo, currencyService)
+
+	// --------------------
+	// EVENT SUBSCRIPTIONS
+	// --------------------
+	bus.Subscribe(event.PriceChanged, favoriteService.OnPriceChanged)
+	bus.Subscribe(event.CarStatusChanged, testDriveService.OnCarStatusChanged)
 
 	// --------------------
 	// HANDLERS
 	// --------------------
-	carHandler := handler.NewCarHandler(carService)
+	carHandler := handler.NewCarHandler(carService, currencyService)
 	auctionHandler := handler.NewAuctionHandler(auctionService)
+	auctionStreamHandler := handler.NewAuctionStreamHandler(auctionService, cfg.StreamAllowedOrigins)
+	auctionOfferHandler := handler.NewAuctionOfferHandler(auctionOfferService)
 	bidHandler := handler.NewBidHandler(auctionService)
 	authHandler := handler.NewAuthHandler(authService)
 	orderHandler := handler.NewOrderHandler(orderService)
-	favoriteHandler := handler.NewFavoriteHandler(favoriteService)
+	favoriteHandler := handler.NewFavoriteHandler(favoriteService, currencyService)
 	tradeInHandler := handler.NewTradeInHandler(tradeInService)
+	reservationHandler := handler.NewReservationHandler(reservationService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService, showroomService)
+	comparisonHandler := handler.NewComparisonHandler(comparisonService, currencyService)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationService, currencyService)
+	currencyHandler := handler.NewCurrencyHandler(currencyService)
+	showroomHandler := handler.NewShowroomHandler(showroomService)
+	documentHandler := handler.NewDocumentHandler(documentService)
+	testDriveHandler := handler.NewTestDriveHandler(testDriveService)
 
 	// --------------------
 	// AUTH (PUBLIC)
@@ -89,6 +181,11 @@ func main() {
 				middleware.AdminOnly(carHandler.UpdateCar),
 			)(w, r)
 
+		case http.MethodPatch:
+			middleware.Auth(
+				middleware.AdminOnly(carHandler.PatchCar),
+			)(w, r)
+
 		case http.MethodDelete:
 			middleware.Auth(
 				middleware.AdminOnly(carHandler.DeleteCar),
@@ -99,6 +196,376 @@ func main() {
 		}
 	})
 
+	// /cars/decode-vin?vin=...
+	// GET -> DecodeVIN (офлайн, без внешних сервисов)
+	http.HandleFunc("/cars/decode-vin", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			carHandler.DecodeVIN(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /cars/compare?ids=1,2,3
+	// GET -> Compare (от 2 до 4 машин)
+	http.HandleFunc("/cars/compare", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			comparisonHandler.Compare(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /cars/{id}/price-history
+	// GET -> GetPriceHistory
+	http.HandleFunc("/cars/{id}/price-history", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			carHandler.GetPriceHistory(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// --------------------
+	// RECOMMENDATIONS
+	// --------------------
+
+	// /cars/{id}/similar?limit=10
+	// GET -> Similar
+	http.HandleFunc("/cars/{id}/similar", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			recommendationHandler.Similar(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /recommendations/me?limit=10
+	// GET -> ForMe
+	http.HandleFunc("/recommendations/me", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			recommendationHandler.ForMe(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /cars/{id}/restore
+	// POST -> RestoreCar (admin)
+	http.HandleFunc("/cars/{id}/restore", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				carHandler.RestoreCar(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /cars/{id}/status
+	// POST -> ChangeStatus (admin)
+	http.HandleFunc("/cars/{id}/status", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				carHandler.ChangeStatus(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /cars/{id}/status-history
+	// GET -> GetStatusHistory (admin)
+	http.HandleFunc("/cars/{id}/status-history", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				carHandler.GetStatusHistory(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /cars/{id}/transfer
+	// POST -> TransferCar (admin, в другой салон)
+	http.HandleFunc("/cars/{id}/transfer", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				carHandler.TransferCar(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /cars/{id}/transfers
+	// GET -> GetTransfers (admin)
+	http.HandleFunc("/cars/{id}/transfers", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				carHandler.GetTransfers(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// --------------------
+	// CAR DOCUMENTS
+	// --------------------
+
+	// /cars/{id}/documents
+	// GET  -> ListForCar (без токена — только публичные)
+	// POST -> Upload (admin, multipart/form-data)
+	http.HandleFunc("/cars/{id}/documents", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middleware.OptionalAuth(documentHandler.ListForCar)(w, r)
+		case http.MethodPost:
+			middleware.Auth(
+				middleware.AdminOnly(documentHandler.Upload),
+			)(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// /documents/{id}
+	// GET    -> Download (видимость проверяется по токену)
+	// DELETE -> Delete (admin)
+	http.HandleFunc("/documents/{id}", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			middleware.OptionalAuth(documentHandler.Download)(w, r)
+		case http.MethodDelete:
+			middleware.Auth(
+				middleware.AdminOnly(documentHandler.Delete),
+			)(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+
+	// --------------------
+	// SHOWROOMS
+	// --------------------
+
+	// /showrooms?city=Almaty
+	// GET -> GetShowrooms
+	http.HandleFunc("/showrooms", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			showroomHandler.GetShowrooms(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /showrooms/{id}
+	// GET -> GetShowroom
+	http.HandleFunc("/showrooms/{id}", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			showroomHandler.GetShowroom(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /admin/showrooms
+	// POST -> CreateShowroom (админ без ограничений по салонам)
+	http.HandleFunc("/admin/showrooms", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				showroomHandler.CreateShowroom(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /admin/showrooms/{id}
+	// PUT -> UpdateShowroom
+	http.HandleFunc("/admin/showrooms/{id}", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPut:
+				showroomHandler.UpdateShowroom(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /admin/users/{id}/showrooms
+	// GET -> GetAdminScope
+	// PUT -> SetAdminScope (пустой список — доступ ко всем салонам)
+	http.HandleFunc("/admin/users/{id}/showrooms", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				showroomHandler.GetAdminScope(w, r)
+			case http.MethodPut:
+				showroomHandler.SetAdminScope(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// --------------------
+	// CURRENCIES
+	// --------------------
+
+	// /exchange-rates
+	// GET -> GetRates
+	http.HandleFunc("/exchange-rates", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			currencyHandler.GetRates(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /admin/exchange-rates/{currency}
+	// PUT -> SetRate (admin)
+	http.HandleFunc("/admin/exchange-rates/{currency}", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPut:
+				currencyHandler.SetRate(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /users/me/currency
+	// PUT -> SetPreferredCurrency (валюта, в которой показывать цены)
+	http.HandleFunc("/users/me/currency", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPut:
+			currencyHandler.SetPreferredCurrency(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// --------------------
+	// ADMIN INVENTORY IMPORT / EXPORT
+	// --------------------
+
+	// /admin/cars/import?format=csv&dry_run=true&all_or_nothing=true
+	// POST -> Import
+	http.HandleFunc("/admin/cars/import", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				inventoryHandler.Import(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /admin/cars/export?format=csv&status=available
+	// GET -> Export
+	http.HandleFunc("/admin/cars/export", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				inventoryHandler.Export(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// --------------------
+	// RESERVATIONS
+	// --------------------
+
+	// /cars/{id}/reserve
+	// POST   -> Reserve
+	// DELETE -> Cancel
+	http.HandleFunc("/cars/{id}/reserve", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			reservationHandler.Reserve(w, r)
+		case http.MethodDelete:
+			reservationHandler.Cancel(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	http.HandleFunc(
+		"/reservations/my",
+		middleware.Auth(reservationHandler.GetMy),
+	)
+
+	// --------------------
+	// TEST DRIVES
+	// --------------------
+
+	// /cars/{id}/test-drives?date=2026-10-20
+	// GET  -> GetSlots
+	// POST -> Book
+	http.HandleFunc("/cars/{id}/test-drives", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			testDriveHandler.GetSlots(w, r)
+		case http.MethodPost:
+			testDriveHandler.Book(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /test-drives/{id}
+	// PATCH  -> Reschedule
+	// DELETE -> Cancel (владелец или админ салона)
+	http.HandleFunc("/test-drives/{id}", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPatch:
+			testDriveHandler.Reschedule(w, r)
+		case http.MethodDelete:
+			testDriveHandler.Cancel(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	http.HandleFunc(
+		"/test-drives/my",
+		middleware.Auth(testDriveHandler.GetMy),
+	)
+
+	// /admin/test-drives?from=2026-10-20&to=2026-10-26&showroom_id=1
+	// GET -> Calendar
+	http.HandleFunc("/admin/test-drives", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				testDriveHandler.Calendar(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
 	// --------------------
 	// TRADE-IN ROUTES
 	// --------------------
@@ -202,6 +669,11 @@ func main() {
 				middleware.AdminOnly(auctionHandler.UpdateAuction),
 			)(w, r)
 
+		case http.MethodPatch:
+			middleware.Auth(
+				middleware.AdminOnly(auctionHandler.PatchAuction),
+			)(w, r)
+
 		case http.MethodDelete:
 			middleware.Auth(
 				middleware.AdminOnly(auctionHandler.DeleteAuction),
@@ -212,6 +684,115 @@ func main() {
 		}
 	})
 
+	// /auctions/upcoming
+	// GET -> GetUpcoming (запланированные, с отсчётом до начала)
+	http.HandleFunc("/auctions/upcoming", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auctionHandler.GetUpcoming(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// --------------------
+	// AUCTION OFFERS
+	// после аукциона, закрытого ниже резервной цены
+	// --------------------
+
+	// /auctions/{id}/offer
+	// POST -> MakeOffer
+	http.HandleFunc("/auctions/{id}/offer", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			auctionOfferHandler.MakeOffer(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	http.HandleFunc(
+		"/auction-offers/my",
+		middleware.Auth(auctionOfferHandler.GetMy),
+	)
+
+	// /admin/auction-offers?status=pending
+	// GET -> GetAll
+	http.HandleFunc("/admin/auction-offers", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				auctionOfferHandler.GetAll(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /admin/auction-offers/{id}/accept
+	// POST -> Accept
+	http.HandleFunc("/admin/auction-offers/{id}/accept", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				auctionOfferHandler.Accept(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// /admin/auction-offers/{id}/reject
+	// POST -> Reject
+	http.HandleFunc("/admin/auction-offers/{id}/reject", middleware.Auth(
+		middleware.AdminOnly(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost:
+				auctionOfferHandler.Reject(w, r)
+			default:
+				http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			}
+		}),
+	))
+
+	// --------------------
+	// AUCTION UPDATES (REAL-TIME)
+	// токен — в заголовке Authorization или в ?stream_token=
+	// --------------------
+
+	// /auctions/{id}/stream-token
+	// POST -> StreamToken (короткоживущий токен для stream и ws)
+	http.HandleFunc("/auctions/{id}/stream-token", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			authHandler.StreamToken(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /auctions/{id}/stream
+	// GET -> Stream (Server-Sent Events)
+	http.HandleFunc("/auctions/{id}/stream", middleware.StreamAuth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auctionStreamHandler.Stream(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /auctions/{id}/ws
+	// GET -> WebSocket
+	http.HandleFunc("/auctions/{id}/ws", middleware.StreamAuth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			auctionStreamHandler.WebSocket(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
 	// --------------------
 	// BIDS
 	// --------------------
@@ -220,6 +801,45 @@ func main() {
 		middleware.Auth(bidHandler.PlaceBid),
 	)
 
+	// /auctions/{id}/bids
+	// GET -> GetBids (история ставок)
+	http.HandleFunc("/auctions/{id}/bids", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			bidHandler.GetBids(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /auctions/{id}/buy-now
+	// POST -> BuyNow
+	http.HandleFunc("/auctions/{id}/buy-now", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			bidHandler.BuyNow(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
+	// /auctions/{id}/proxy-bid
+	// GET    -> GetProxyBid
+	// PUT    -> SetProxyBid (задать или поднять максимум)
+	// DELETE -> CancelProxyBid
+	http.HandleFunc("/auctions/{id}/proxy-bid", middleware.Auth(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			bidHandler.GetProxyBid(w, r)
+		case http.MethodPut:
+			bidHandler.SetProxyBid(w, r)
+		case http.MethodDelete:
+			bidHandler.CancelProxyBid(w, r)
+		default:
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	}))
+
 	// --------------------
 	// FAVORITES
 	// --------------------
@@ -252,15 +872,53 @@ func main() {
 		middleware.Auth(orderHandler.GetMy),
 	)
 
+	// --------------------
+	// NOTIFICATIONS
+	// --------------------
+	http.HandleFunc(
+		"/notifications/my",
+		middleware.Auth(notificationHandler.GetMy),
+	)
+
+	http.HandleFunc(
+		"/notifications/read",
+		middleware.Auth(notificationHandler.MarkRead),
+	)
+
 	// --------------------
 	// BACKGROUND WORKER
 	// --------------------
+	// аукционы открываются и рассчитываются точно в срок по очереди событий
+	go auctionService.RunScheduler()
+
+	// сверка с БД: аукционы других инстансов и брошенные расчёты
+	go func() {
+		ticker := time.NewTicker(cfg.AuctionReconcile)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			auctionService.Reconcile()
+		}
+	}()
+
+	// снятие просроченных броней
+	go func() {
+		ticker := time.NewTicker(cfg.ReservationCheck)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			reservationService.ExpireLapsed()
+		}
+	}()
+
+	// отклонение тест-драйвов на проданные и выставленные на аукцион машины:
+	// обычно это делает подписчик на CarStatusChanged, тикер — страховка
 	go func() {
-		ticker := time.NewTicker(5 * time.Second)
+		ticker := time.NewTicker(cfg.TestDriveCheck)
 		defer ticker.Stop()
 
 		for range ticker.C {
-			auctionService.CheckAuctionsEvery5Sec()
+			testDriveService.RejectUnavailable()
 		}
 	}()
 
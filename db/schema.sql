@@ -4,46 +4,206 @@ CREATE TABLE users (
                        email TEXT NOT NULL,
                        password_hash TEXT,
                        role TEXT NOT NULL,
+                       preferred_currency TEXT,
                        created_at TIMESTAMP DEFAULT NOW()
 );
 
+-- EXCHANGE RATES (сколько тенге за единицу валюты)
+CREATE TABLE exchange_rates (
+                                currency TEXT PRIMARY KEY,
+                                kzt_per_unit NUMERIC NOT NULL CHECK (kzt_per_unit > 0),
+                                updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                                updated_at TIMESTAMP DEFAULT NOW()
+);
+
+INSERT INTO exchange_rates (currency, kzt_per_unit) VALUES
+    ('KZT', 1),
+    ('USD', 450),
+    ('RUB', 5);
+
+-- SHOWROOMS
+CREATE TABLE showrooms (
+                           id BIGSERIAL PRIMARY KEY,
+                           name TEXT NOT NULL,
+                           city TEXT NOT NULL,
+                           address TEXT NOT NULL,
+                           latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+                           longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+                           working_hours JSONB NOT NULL DEFAULT '[]',
+                           created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_showrooms_city ON showrooms (LOWER(city));
+
+-- ADMIN SHOWROOM SCOPES (нет строк у админа — доступ ко всем салонам)
+CREATE TABLE admin_showrooms (
+                                 user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                 showroom_id BIGINT NOT NULL REFERENCES showrooms(id) ON DELETE CASCADE,
+                                 PRIMARY KEY (user_id, showroom_id)
+);
+
 -- CARS
 CREATE TABLE cars (
                       id BIGSERIAL PRIMARY KEY,
                       brand TEXT NOT NULL,
                       model TEXT NOT NULL,
                       year INT NOT NULL,
-                      price NUMERIC,
+                      price NUMERIC(14, 2),
+                      currency TEXT NOT NULL DEFAULT 'USD' REFERENCES exchange_rates(currency),
                       status TEXT NOT NULL DEFAULT 'available',
                       is_auction_only BOOLEAN DEFAULT FALSE,
-                      created_at TIMESTAMP DEFAULT NOW()
+                      vin TEXT UNIQUE,
+                      external_id TEXT UNIQUE,
+                      country TEXT,
+                      body_type TEXT,
+                      showroom_id BIGINT REFERENCES showrooms(id) ON DELETE RESTRICT,
+                      version INT NOT NULL DEFAULT 1,
+                      created_at TIMESTAMP DEFAULT NOW(),
+                      deleted_at TIMESTAMP,
+
+                      CONSTRAINT chk_cars_status
+                          CHECK (status IN ('available', 'reserved', 'on_auction', 'sold', 'withdrawn'))
+);
+
+CREATE INDEX idx_cars_showroom ON cars (showroom_id);
+
+-- CAR TRANSFERS (перемещения между салонами)
+CREATE TABLE car_transfers (
+                               id BIGSERIAL PRIMARY KEY,
+                               car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                               from_showroom_id BIGINT REFERENCES showrooms(id) ON DELETE SET NULL,
+                               to_showroom_id BIGINT NOT NULL REFERENCES showrooms(id) ON DELETE RESTRICT,
+                               transferred_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                               reason TEXT,
+                               created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_car_transfers_car ON car_transfers (car_id, created_at);
+
+-- CAR DOCUMENTS (сами файлы — во внешнем хранилище по storage_key)
+CREATE TABLE car_documents (
+                               id BIGSERIAL PRIMARY KEY,
+                               car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                               doc_type TEXT NOT NULL,
+                               visibility TEXT NOT NULL DEFAULT 'admin_only',
+                               file_name TEXT NOT NULL,
+                               content_type TEXT NOT NULL,
+                               size_bytes BIGINT NOT NULL,
+                               storage_key TEXT NOT NULL UNIQUE,
+                               uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                               created_at TIMESTAMP DEFAULT NOW(),
+
+                               CONSTRAINT chk_car_documents_type
+                                   CHECK (doc_type IN ('inspection', 'service_record', 'registration')),
+                               CONSTRAINT chk_car_documents_visibility
+                                   CHECK (visibility IN ('public', 'buyers', 'admin_only'))
+);
+
+CREATE INDEX idx_car_documents_car ON car_documents (car_id, created_at);
+
+-- CAR STATUS HISTORY
+CREATE TABLE car_status_history (
+                                    id BIGSERIAL PRIMARY KEY,
+                                    car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                                    from_status TEXT NOT NULL,
+                                    to_status TEXT NOT NULL,
+                                    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                                    reason TEXT NOT NULL DEFAULT '',
+                                    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- CAR PRICE HISTORY
+CREATE TABLE car_price_history (
+                                   id BIGSERIAL PRIMARY KEY,
+                                   car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                                   old_price NUMERIC(14, 2),
+                                   old_currency TEXT NOT NULL DEFAULT 'USD',
+                                   new_price NUMERIC(14, 2),
+                                   new_currency TEXT NOT NULL DEFAULT 'USD',
+                                   changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                                   created_at TIMESTAMP DEFAULT NOW()
 );
 
+CREATE INDEX idx_car_price_history_car ON car_price_history (car_id, created_at);
+
 -- AUCTIONS
 CREATE TABLE auctions (
                           id BIGSERIAL PRIMARY KEY,
-                          car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
-                          start_price NUMERIC NOT NULL,
+                          car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE RESTRICT,
+                          start_price NUMERIC(14, 2) NOT NULL,
+                          -- скрытая минимальная цена продажи, NULL — без резерва
+                          reserve_price NUMERIC(14, 2),
+                          -- цена мгновенной покупки, NULL — без «купить сейчас»
+                          buy_now_price NUMERIC(14, 2),
                           start_time TIMESTAMP NOT NULL,
                           end_time TIMESTAMP NOT NULL,
-                          created_at TIMESTAMP DEFAULT NOW()
+                          -- конец по расписанию; end_time может быть позже из-за soft close
+                          scheduled_end_time TIMESTAMP NOT NULL,
+                          status TEXT NOT NULL DEFAULT 'scheduled',
+                          -- когда процесс взялся за расчёт; зависший захват можно перехватить
+                          settle_claimed_at TIMESTAMP,
+                          settled_at TIMESTAMP,
+                          version INT NOT NULL DEFAULT 1,
+                          created_at TIMESTAMP DEFAULT NOW(),
+
+                          CONSTRAINT chk_auctions_status
+                              CHECK (status IN ('scheduled', 'active', 'ended', 'settled', 'cancelled', 'no_sale'))
 );
 
+-- планировщик ищет аукционы, которые пора начать или рассчитать
+CREATE INDEX idx_auctions_status_end ON auctions (status, end_time);
+
 -- BIDS
 CREATE TABLE bids (
                       id BIGSERIAL PRIMARY KEY,
                       auction_id BIGINT NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
                       user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
-                      amount NUMERIC NOT NULL,
+                      amount NUMERIC(14, 2) NOT NULL,
+                      -- ставка сделана системой по максимуму пользователя
+                      is_auto BOOLEAN NOT NULL DEFAULT FALSE,
                       created_at TIMESTAMP DEFAULT NOW()
 );
 
+-- AUCTION PROXY BIDS
+-- скрытый максимум пользователя, до которого система ставит за него сама
+CREATE TABLE auction_proxy_bids (
+                                    auction_id BIGINT NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+                                    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                    max_amount NUMERIC(14, 2) NOT NULL,
+                                    created_at TIMESTAMP DEFAULT NOW(),
+                                    -- при равных максимумах побеждает тот, кто задал сумму раньше
+                                    updated_at TIMESTAMP DEFAULT NOW(),
+
+                                    PRIMARY KEY (auction_id, user_id)
+);
+
+-- AUCTION OFFERS
+-- предложения цены от лидера аукциона, закрытого без достижения резерва
+CREATE TABLE auction_offers (
+                                id BIGSERIAL PRIMARY KEY,
+                                auction_id BIGINT NOT NULL REFERENCES auctions(id) ON DELETE CASCADE,
+                                user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                                amount NUMERIC(14, 2) NOT NULL,
+                                status TEXT NOT NULL DEFAULT 'pending',
+                                decided_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+                                decided_at TIMESTAMP,
+                                created_at TIMESTAMP DEFAULT NOW(),
+
+                                CONSTRAINT chk_auction_offers_status
+                                    CHECK (status IN ('pending', 'accepted', 'rejected'))
+);
+
+-- по аукциону рассматривается одно предложение за раз
+CREATE UNIQUE INDEX uq_auction_offers_pending
+    ON auction_offers (auction_id) WHERE status = 'pending';
+
 -- ORDERS
 CREATE TABLE orders (
                         id BIGSERIAL PRIMARY KEY,
                         user_id BIGINT NOT NULL,
                         car_id BIGINT NOT NULL UNIQUE,
-                        total_price NUMERIC NOT NULL,
+                        total_price NUMERIC(14, 2) NOT NULL,
+                        currency TEXT NOT NULL DEFAULT 'USD',
                         source TEXT NOT NULL CHECK (source IN ('auction', 'direct')),
                         created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
 
@@ -55,7 +215,7 @@ CREATE TABLE orders (
                         CONSTRAINT fk_orders_car
                             FOREIGN KEY (car_id)
                                 REFERENCES cars(id)
-                                ON DELETE CASCADE
+                                ON DELETE RESTRICT
 );
 
 
@@ -88,8 +248,8 @@ CREATE TABLE tradeins (
 
                           desired_car_id BIGINT NOT NULL,
 
-                          estimated_price NUMERIC,
-                          user_payment NUMERIC,
+                          estimated_price NUMERIC(14, 2),
+                          user_payment NUMERIC(14, 2),
 
                           status TEXT NOT NULL DEFAULT 'pending',
 
@@ -106,7 +266,7 @@ CREATE TABLE tradeins (
                           CONSTRAINT fk_tradeins_desired_car
                               FOREIGN KEY (desired_car_id)
                                   REFERENCES cars(id)
-                                  ON DELETE CASCADE,
+                                  ON DELETE RESTRICT,
 
     -- --------------------
     -- CHECK CONSTRAINTS
@@ -116,3 +276,56 @@ CREATE TABLE tradeins (
 );
 
 
+-- RESERVATIONS
+CREATE TABLE reservations (
+                              id BIGSERIAL PRIMARY KEY,
+                              car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                              user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                              deposit NUMERIC(14, 2) NOT NULL DEFAULT 0,
+                              status TEXT NOT NULL DEFAULT 'active',
+                              expires_at TIMESTAMP NOT NULL,
+                              created_at TIMESTAMP DEFAULT NOW(),
+
+                              CONSTRAINT chk_reservations_status
+                                  CHECK (status IN ('active', 'cancelled', 'expired', 'completed'))
+);
+
+-- одна активная бронь на машину
+CREATE UNIQUE INDEX uq_reservations_active_car
+    ON reservations (car_id) WHERE status = 'active';
+
+-- TEST DRIVES
+CREATE TABLE test_drives (
+                             id BIGSERIAL PRIMARY KEY,
+                             car_id BIGINT NOT NULL REFERENCES cars(id) ON DELETE CASCADE,
+                             user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                             showroom_id BIGINT NOT NULL REFERENCES showrooms(id) ON DELETE RESTRICT,
+                             starts_at TIMESTAMP NOT NULL,
+                             ends_at TIMESTAMP NOT NULL,
+                             status TEXT NOT NULL DEFAULT 'booked',
+                             reason TEXT NOT NULL DEFAULT '',
+                             created_at TIMESTAMP DEFAULT NOW(),
+                             updated_at TIMESTAMP DEFAULT NOW(),
+
+                             CONSTRAINT chk_test_drives_status
+                                 CHECK (status IN ('booked', 'cancelled', 'rejected')),
+                             CONSTRAINT chk_test_drives_period
+                                 CHECK (ends_at > starts_at)
+);
+
+-- одна запись на машину и одна запись пользователя на каждый слот
+CREATE UNIQUE INDEX uq_test_drives_car_slot
+    ON test_drives (car_id, starts_at) WHERE status = 'booked';
+CREATE UNIQUE INDEX uq_test_drives_user_slot
+    ON test_drives (user_id, starts_at) WHERE status = 'booked';
+CREATE INDEX idx_test_drives_showroom ON test_drives (showroom_id, starts_at);
+
+-- NOTIFICATIONS
+CREATE TABLE notifications (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+                               kind TEXT NOT NULL,
+                               message TEXT NOT NULL,
+                               is_read BOOLEAN NOT NULL DEFAULT FALSE,
+                               created_at TIMESTAMP DEFAULT NOW()
+);
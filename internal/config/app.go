@@ -0,0 +1,199 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+// AppConfig — настройки бизнес-логики. Каждое значение можно
+// переопределить переменной окружения, иначе берётся значение по умолчанию.
+type AppConfig struct {
+	// Бронирование машины перед покупкой
+	ReservationWindow  time.Duration // CARSTORE_RESERVATION_WINDOW, например "48h"
+	ReservationDeposit money.Money   // CARSTORE_RESERVATION_DEPOSIT, 0 — без депозита
+	ReservationCheck   time.Duration // CARSTORE_RESERVATION_CHECK_INTERVAL
+
+	// Каталог для документов машин на локальном диске
+	DocumentsDir string // CARSTORE_DOCUMENTS_DIR
+
+	// Шаг ставки по ступеням цены
+	BidIncrements []model.BidIncrement // CARSTORE_BID_INCREMENTS, например "5000:50,20000:100,*:250"
+
+	// Продление аукциона ставками в последние минуты
+	AuctionSoftCloseWindow    time.Duration // CARSTORE_AUCTION_SOFT_CLOSE_WINDOW, 0 — выключено
+	AuctionSoftCloseExtension time.Duration // CARSTORE_AUCTION_SOFT_CLOSE_EXTENSION
+	AuctionSoftCloseMax       time.Duration // CARSTORE_AUCTION_SOFT_CLOSE_MAX, предел продления
+
+	// Как часто сверять очередь планировщика аукционов с БД
+	AuctionReconcile time.Duration // CARSTORE_AUCTION_RECONCILE_INTERVAL
+
+	// Минимальная длительность аукциона от начала до конца
+	AuctionMinDuration time.Duration // CARSTORE_AUCTION_MIN_DURATION
+
+	// «Купить сейчас» пропадает, когда ставки доходят до этого процента от buy_now_price
+	AuctionBuyNowCutoff int // CARSTORE_AUCTION_BUY_NOW_CUTOFF, 0 — пропадает после первой ставки
+
+	// Тест-драйвы
+	TestDriveSlot    time.Duration // CARSTORE_TEST_DRIVE_SLOT, длина одного слота
+	TestDriveHorizon time.Duration // CARSTORE_TEST_DRIVE_HORIZON, на сколько вперёд можно записаться
+	TestDriveCheck   time.Duration // CARSTORE_TEST_DRIVE_CHECK_INTERVAL
+
+	// Страницы других хостов, которым можно открывать WebSocket аукционов
+	StreamAllowedOrigins []string // CARSTORE_STREAM_ALLOWED_ORIGINS, через запятую
+
+	// Часовой пояс, в котором заданы часы работы салонов
+	Location *time.Location // CARSTORE_TIMEZONE, например "Asia/Almaty"
+}
+
+func Load() AppConfig {
+	return AppConfig{
+		ReservationWindow:  getEnvDuration("CARSTORE_RESERVATION_WINDOW", 48*time.Hour),
+		ReservationDeposit: getEnvMoney("CARSTORE_RESERVATION_DEPOSIT", 0),
+		ReservationCheck:   getEnvDuration("CARSTORE_RESERVATION_CHECK_INTERVAL", time.Minute),
+		DocumentsDir:       getEnvString("CARSTORE_DOCUMENTS_DIR", "data/documents"),
+		BidIncrements: getEnvIncrements("CARSTORE_BID_INCREMENTS", []model.BidIncrement{
+			{Below: money.FromUnits(5000), Step: money.FromUnits(50)},
+			{Below: money.FromUnits(20000), Step: money.FromUnits(100)},
+			{Below: money.FromUnits(50000), Step: money.FromUnits(250)},
+			{Below: money.FromUnits(100000), Step: money.FromUnits(500)},
+			{Step: money.FromUnits(1000)},
+		}),
+		AuctionSoftCloseWindow:    getEnvDuration("CARSTORE_AUCTION_SOFT_CLOSE_WINDOW", 2*time.Minute),
+		AuctionSoftCloseExtension: getEnvDuration("CARSTORE_AUCTION_SOFT_CLOSE_EXTENSION", 2*time.Minute),
+		AuctionSoftCloseMax:       getEnvDuration("CARSTORE_AUCTION_SOFT_CLOSE_MAX", 30*time.Minute),
+		AuctionMinDuration:        getEnvDuration("CARSTORE_AUCTION_MIN_DURATION", time.Hour),
+		AuctionBuyNowCutoff:       getEnvInt("CARSTORE_AUCTION_BUY_NOW_CUTOFF", 50),
+		AuctionReconcile:          getEnvDuration("CARSTORE_AUCTION_RECONCILE_INTERVAL", time.Minute),
+		TestDriveSlot:             getEnvDuration("CARSTORE_TEST_DRIVE_SLOT", time.Hour),
+		TestDriveHorizon:          getEnvDuration("CARSTORE_TEST_DRIVE_HORIZON", 14*24*time.Hour),
+		TestDriveCheck:            getEnvDuration("CARSTORE_TEST_DRIVE_CHECK_INTERVAL", time.Minute),
+		// dev-сервер фронтенда
+		StreamAllowedOrigins: getEnvList("CARSTORE_STREAM_ALLOWED_ORIGINS", []string{"http://localhost:5173"}),
+		// по умолчанию UTC+5 — единое время Казахстана
+		Location: getEnvLocation("CARSTORE_TIMEZONE", time.FixedZone("UTC+5", 5*60*60)),
+	}
+}
+
+func getEnvString(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func getEnvInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+func getEnvList(key string, def []string) []string {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	var list []string
+	for _, part := range strings.Split(v, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
+
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+func getEnvLocation(key string, def *time.Location) *time.Location {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	loc, err := time.LoadLocation(v)
+	if err != nil {
+		return def
+	}
+	return loc
+}
+
+// getEnvIncrements читает ступени шага ставки (см. parseIncrements).
+// Ошибка в значении — предупреждение в лог и def.
+func getEnvIncrements(key string, def []model.BidIncrement) []model.BidIncrement {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	table, err := parseIncrements(v)
+	if err != nil {
+		log.Printf("config: %s=%q is invalid (%v), using defaults\n", key, v, err)
+		return def
+	}
+	return table
+}
+
+// parseIncrements разбирает ступени вида "до:шаг" через запятую, по возрастанию;
+// у последней вместо границы может стоять "*"
+func parseIncrements(v string) ([]model.BidIncrement, error) {
+	parts := strings.Split(v, ",")
+	table := make([]model.BidIncrement, 0, len(parts))
+	for i, part := range parts {
+		below, step, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("tier %q: expected below:step", part)
+		}
+
+		var inc model.BidIncrement
+		var err error
+		if inc.Step, err = money.Parse(strings.TrimSpace(step)); err != nil || inc.Step <= 0 {
+			return nil, fmt.Errorf("tier %q: step must be a positive amount", part)
+		}
+		if below = strings.TrimSpace(below); below == "*" {
+			if i != len(parts)-1 {
+				return nil, fmt.Errorf("tier %q: only the last tier can be open-ended", part)
+			}
+		} else {
+			if inc.Below, err = money.Parse(below); err != nil || inc.Below <= 0 {
+				return nil, fmt.Errorf("tier %q: bound must be a positive amount", part)
+			}
+			if i > 0 && inc.Below <= table[i-1].Below {
+				return nil, fmt.Errorf("tier %q: bounds must increase", part)
+			}
+		}
+		table = append(table, inc)
+	}
+	return table, nil
+}
+
+func getEnvMoney(key string, def money.Money) money.Money {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	m, err := money.Parse(v)
+	if err != nil {
+		return def
+	}
+	return m
+}
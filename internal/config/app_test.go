@@ -0,0 +1,48 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+
+	"car-store/internal/model"
+)
+
+func TestParseIncrements(t *testing.T) {
+	got, err := parseIncrements("5000:50, 20000:100 ,*:250")
+	if err != nil {
+		t.Fatalf("parseIncrements error: %v", err)
+	}
+	want := []model.BidIncrement{
+		{Below: 500000, Step: 5000},
+		{Below: 2000000, Step: 10000},
+		{Below: 0, Step: 25000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseIncrements = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseIncrementsInvalid(t *testing.T) {
+	for _, v := range []string{
+		"5000",           // нет шага
+		"5000:0",         // нулевой шаг
+		"5000:-1",        // отрицательный шаг
+		"abc:50",         // граница не число
+		"0:50",           // нулевая граница
+		"*:250,5000:50",  // "*" не последней
+		"5000:50,5000:1", // границы не растут
+		"5000:50,1000:1", // границы убывают
+	} {
+		if _, err := parseIncrements(v); err == nil {
+			t.Errorf("parseIncrements(%q) error = nil, want error", v)
+		}
+	}
+}
+
+func TestGetEnvIncrementsFallsBack(t *testing.T) {
+	def := []model.BidIncrement{{Step: 100}}
+	t.Setenv("CARSTORE_TEST_INCREMENTS", "5000:50,oops")
+	if got := getEnvIncrements("CARSTORE_TEST_INCREMENTS", def); !reflect.DeepEqual(got, def) {
+		t.Errorf("getEnvIncrements = %+v, want defaults %+v", got, def)
+	}
+}
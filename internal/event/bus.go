@@ -0,0 +1,48 @@
+package event
+
+import (
+	"log"
+	"sync"
+)
+
+// Event — доменное событие. Name используется для подписки.
+type Event interface {
+	Name() string
+}
+
+type Handler func(e Event)
+
+// Bus — простая in-process шина событий.
+// Обработчики вызываются синхронно в порядке подписки,
+// паника в одном обработчике не мешает остальным.
+type Bus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+}
+
+func NewBus() *Bus {
+	return &Bus{handlers: make(map[string][]Handler)}
+}
+
+func (b *Bus) Subscribe(name string, h Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], h)
+}
+
+func (b *Bus) Publish(e Event) {
+	b.mu.RLock()
+	handlers := b.handlers[e.Name()]
+	b.mu.RUnlock()
+
+	for _, h := range handlers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					log.Printf("event %s handler panic: %v\n", e.Name(), r)
+				}
+			}()
+			h(e)
+		}()
+	}
+}
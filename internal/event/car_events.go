@@ -0,0 +1,33 @@
+package event
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+const PriceChanged = "price_changed"
+
+type PriceChangedEvent struct {
+	CarID       int64
+	OldPrice    money.Money
+	OldCurrency string
+	NewPrice    money.Money
+	NewCurrency string
+	ChangedBy   *int64 // nil — импорт или система
+	ChangedAt   time.Time
+}
+
+func (PriceChangedEvent) Name() string { return PriceChanged }
+
+const CarStatusChanged = "car_status_changed"
+
+type CarStatusChangedEvent struct {
+	CarID     int64
+	From      string
+	To        string
+	ChangedBy *int64 // nil — заказ, аукцион или система
+	ChangedAt time.Time
+}
+
+func (CarStatusChangedEvent) Name() string { return CarStatusChanged }
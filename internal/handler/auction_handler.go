@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 
+	"car-store/internal/middleware"
 	"car-store/internal/model"
 	"car-store/internal/service"
 )
@@ -19,19 +20,36 @@ func NewAuctionHandler(service *service.AuctionService) *AuctionHandler {
 }
 
 func (h *AuctionHandler) CreateAuction(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
 	var a model.Auction
 	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.CreateAuction(&a); err != nil {
+	if err := h.service.CreateAuction(&a, adminID); err != nil {
 		if errors.Is(err, service.ErrCarNotFound) {
 			http.Error(w, err.Error(), http.StatusBadRequest) // 400
 			return
 		}
 
-		if errors.Is(err, service.ErrCarAlreadyOnAuction) {
+		if errors.Is(err, service.ErrShowroomAccessDenied) {
+			http.Error(w, err.Error(), http.StatusForbidden) // 403
+			return
+		}
+
+		if errors.Is(err, service.ErrInvalidAuction) {
+			http.Error(w, err.Error(), http.StatusBadRequest) // 400
+			return
+		}
+
+		if errors.Is(err, service.ErrCarAlreadyOnAuction) ||
+			errors.Is(err, service.ErrCarNotAuctionable) ||
+			errors.Is(err, service.ErrCarAlreadySold) ||
+			errors.Is(err, service.ErrInvalidStatusTransition) ||
+			errors.Is(err, service.ErrCarArchived) ||
+			errors.Is(err, service.ErrCarStatusConflict) {
 			http.Error(w, err.Error(), http.StatusConflict) // 409
 			return
 		}
@@ -66,6 +84,8 @@ func (h *AuctionHandler) GetAuctions(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		hideReservePrice(r, auction)
+		setETag(w, auction.Version)
 		_ = json.NewEncoder(w).Encode(auction)
 		return
 	}
@@ -77,9 +97,37 @@ func (h *AuctionHandler) GetAuctions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	for i := range auctions {
+		hideReservePrice(r, &auctions[i])
+	}
 	_ = json.NewEncoder(w).Encode(auctions)
 }
 
+// GET /auctions/upcoming — ещё не начавшиеся аукционы с отсчётом starts_in
+func (h *AuctionHandler) GetUpcoming(w http.ResponseWriter, r *http.Request) {
+	auctions, err := h.service.GetUpcoming()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if auctions == nil {
+		auctions = []model.Auction{}
+	}
+	for i := range auctions {
+		hideReservePrice(r, &auctions[i])
+	}
+	writeJSON(w, http.StatusOK, auctions)
+}
+
+// hideReservePrice — резервную цену видят только админы, остальным
+// достаточно reserve_met
+func hideReservePrice(r *http.Request, a *model.Auction) {
+	if role, _ := r.Context().Value(middleware.RoleKey).(string); role != "admin" {
+		a.ReservePrice = nil
+	}
+}
+
+// PUT /auctions?id=123 — полная замена, If-Match необязателен
 func (h *AuctionHandler) UpdateAuction(w http.ResponseWriter, r *http.Request) {
 	idStr := r.URL.Query().Get("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -88,6 +136,12 @@ func (h *AuctionHandler) UpdateAuction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	version, _, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	var a model.Auction
 	if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
@@ -96,12 +150,50 @@ func (h *AuctionHandler) UpdateAuction(w http.ResponseWriter, r *http.Request) {
 
 	a.ID = id
 
-	if err := h.service.UpdateAuction(&a); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+	updated, err := h.service.UpdateAuction(&a, version, adminID)
+	if err != nil {
+		writeAuctionError(w, err)
 		return
 	}
 
-	w.WriteHeader(http.StatusOK)
+	setETag(w, updated.Version)
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// PATCH /auctions?id=123 — частичное обновление, требует If-Match
+func (h *AuctionHandler) PatchAuction(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	version, present, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !present {
+		http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+		return
+	}
+
+	var patch model.AuctionPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+	updated, err := h.service.PatchAuction(id, patch, version, adminID)
+	if err != nil {
+		writeAuctionError(w, err)
+		return
+	}
+
+	setETag(w, updated.Version)
+	writeJSON(w, http.StatusOK, updated)
 }
 
 func (h *AuctionHandler) DeleteAuction(w http.ResponseWriter, r *http.Request) {
@@ -112,10 +204,29 @@ func (h *AuctionHandler) DeleteAuction(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.DeleteAuction(id); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+	if err := h.service.DeleteAuction(id, adminID); err != nil {
+		writeAuctionError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func writeAuctionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAuctionNotFound),
+		errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidAuction):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, service.ErrAuctionFinished):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
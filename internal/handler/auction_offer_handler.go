@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/money"
+	"car-store/internal/service"
+)
+
+type AuctionOfferHandler struct {
+	service *service.AuctionOfferService
+}
+
+func NewAuctionOfferHandler(service *service.AuctionOfferService) *AuctionOfferHandler {
+	return &AuctionOfferHandler{service: service}
+}
+
+type AuctionOfferRequest struct {
+	Amount money.Money `json:"amount"`
+}
+
+// POST /auctions/{id}/offer — лидер аукциона, закрытого ниже резерва, предлагает цену
+func (h *AuctionOfferHandler) MakeOffer(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	var req AuctionOfferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	offer, err := h.service.MakeOffer(userID, auctionID, req.Amount)
+	if err != nil {
+		writeAuctionOfferError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, offer)
+}
+
+// GET /auction-offers/my
+func (h *AuctionOfferHandler) GetMy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	list, err := h.service.GetMyOffers(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []model.AuctionOffer{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// ADMIN: GET /admin/auction-offers?status=pending
+func (h *AuctionOfferHandler) GetAll(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.GetOffers(r.URL.Query().Get("status"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []model.AuctionOffer{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// ADMIN: POST /admin/auction-offers/{id}/accept — создаёт заказ по предложенной цене
+func (h *AuctionOfferHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.AcceptOffer)
+}
+
+// ADMIN: POST /admin/auction-offers/{id}/reject
+func (h *AuctionOfferHandler) Reject(w http.ResponseWriter, r *http.Request) {
+	h.decide(w, r, h.service.RejectOffer)
+}
+
+func (h *AuctionOfferHandler) decide(
+	w http.ResponseWriter,
+	r *http.Request,
+	decide func(offerID, adminID int64) (*model.AuctionOffer, error),
+) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid offer id", http.StatusBadRequest)
+		return
+	}
+
+	offer, err := decide(id, adminID)
+	if err != nil {
+		writeAuctionOfferError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, offer)
+}
+
+func writeAuctionOfferError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrOfferNotFound),
+		errors.Is(err, service.ErrAuctionNotFound),
+		errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidOffer):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrOfferNotAllowed),
+		errors.Is(err, service.ErrOfferPending),
+		errors.Is(err, service.ErrOfferDecided),
+		errors.Is(err, service.ErrCarAlreadySold),
+		errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, service.ErrCarStatusConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/service"
+	"car-store/internal/websocket"
+)
+
+const (
+	// как часто отправлять heartbeat, чтобы прокси не закрывали простаивающий поток
+	streamHeartbeat = 15 * time.Second
+	// сколько ждать записи одного события медленному клиенту
+	streamWriteTimeout = 10 * time.Second
+	// WebSocket-клиент без pong дольше этого времени считается отключившимся
+	wsPongWait = 2 * streamHeartbeat
+)
+
+type AuctionStreamHandler struct {
+	service  *service.AuctionService
+	upgrader *websocket.Upgrader
+}
+
+func NewAuctionStreamHandler(service *service.AuctionService, allowedOrigins []string) *AuctionStreamHandler {
+	return &AuctionStreamHandler{
+		service:  service,
+		upgrader: &websocket.Upgrader{AllowedOrigins: allowedOrigins},
+	}
+}
+
+func (h *AuctionStreamHandler) subscribe(w http.ResponseWriter, r *http.Request) (*service.AuctionSubscription, model.AuctionUpdate, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return nil, model.AuctionUpdate{}, false
+	}
+
+	sub, snapshot, err := h.service.Subscribe(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAuctionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrAuctionFinished):
+			http.Error(w, err.Error(), http.StatusGone)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return nil, model.AuctionUpdate{}, false
+	}
+	return sub, snapshot, true
+}
+
+// --------------------
+// GET /auctions/{id}/stream — Server-Sent Events
+// первым приходит событие snapshot, дальше — bid, price_changed,
+// end_time_changed и finalized/deleted, после которых поток закрывается
+// --------------------
+func (h *AuctionStreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	sub, snapshot, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx не должен буферизовать поток
+	w.WriteHeader(http.StatusOK)
+
+	send := func(event string, payload any) error {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return err
+		}
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+
+	if err := send(snapshot.Type, snapshot); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case u, open := <-sub.Updates():
+			if !open {
+				if sub.Lagged() {
+					_ = send("error", map[string]string{"error": "client is too slow, reconnect to resume"})
+				}
+				return
+			}
+			if err := send(u.Type, u); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			// строка-комментарий: EventSource её игнорирует
+			_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// --------------------
+// GET /auctions/{id}/ws — те же события, что в /stream, по WebSocket:
+// каждое событие — текстовое сообщение с JSON AuctionUpdate
+// --------------------
+func (h *AuctionStreamHandler) WebSocket(w http.ResponseWriter, r *http.Request) {
+	sub, snapshot, ok := h.subscribe(w, r)
+	if !ok {
+		return
+	}
+	defer sub.Close()
+
+	conn, err := h.upgrader.Upgrade(w, r)
+	if err != nil {
+		return
+	}
+
+	// читатель нужен, чтобы обрабатывать ping/pong/close от клиента;
+	// сообщения с данными от клиента не ожидаются и отбрасываются
+	done := make(chan struct{})
+	_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	conn.SetPongHandler(func() {
+		_ = conn.SetReadDeadline(time.Now().Add(wsPongWait))
+	})
+	go func() {
+		defer close(done)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	send := func(u model.AuctionUpdate) error {
+		data, err := json.Marshal(u)
+		if err != nil {
+			return err
+		}
+		return conn.WriteText(data)
+	}
+
+	if err := send(snapshot); err != nil {
+		conn.Close(websocket.CloseGoingAway, "")
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-done:
+			conn.Close(websocket.CloseGoingAway, "")
+			return
+
+		case u, open := <-sub.Updates():
+			if !open {
+				if sub.Lagged() {
+					conn.Close(websocket.CloseTryAgainLater, "client is too slow")
+				} else {
+					conn.Close(websocket.CloseNormal, "auction closed")
+				}
+				return
+			}
+			if err := send(u); err != nil {
+				conn.Close(websocket.CloseGoingAway, "")
+				return
+			}
+
+		case <-heartbeat.C:
+			if err := conn.Ping(); err != nil {
+				conn.Close(websocket.CloseGoingAway, "")
+				return
+			}
+		}
+	}
+}
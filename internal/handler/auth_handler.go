@@ -3,7 +3,9 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"car-store/internal/middleware"
 	"car-store/internal/service"
 )
 
@@ -46,3 +48,27 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		"access_token": token,
 	})
 }
+
+// POST /auctions/{id}/stream-token — токен для EventSource/WebSocket,
+// которые не умеют передавать Authorization
+func (h *AuthHandler) StreamToken(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	token, err := h.auth.IssueStreamToken(userID, role, auctionID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"stream_token": token,
+		"expires_in":   int(service.StreamTokenTTL.Seconds()),
+	})
+}
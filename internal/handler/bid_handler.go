@@ -2,9 +2,13 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+	"strconv"
 
 	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/money"
 	"car-store/internal/service"
 )
 
@@ -18,8 +22,8 @@ func NewBidHandler(auctionService *service.AuctionService) *BidHandler {
 
 // ❗ user_id УБРАН из body
 type PlaceBidRequest struct {
-	AuctionID int64   `json:"auction_id"`
-	Amount    float64 `json:"amount"`
+	AuctionID int64       `json:"auction_id"`
+	Amount    money.Money `json:"amount"`
 }
 
 func (h *BidHandler) PlaceBid(w http.ResponseWriter, r *http.Request) {
@@ -37,9 +41,141 @@ func (h *BidHandler) PlaceBid(w http.ResponseWriter, r *http.Request) {
 		userID,
 		req.Amount,
 	); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeBidError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
 }
+
+// GET /auctions/{id}/bids — история ставок; чужие участники под псевдонимами
+func (h *BidHandler) GetBids(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	list, err := h.auctionService.GetBidHistory(auctionID, userID, role == "admin")
+	if err != nil {
+		writeBidError(w, err)
+		return
+	}
+	if list == nil {
+		list = []model.BidHistoryItem{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+type ProxyBidRequest struct {
+	MaxAmount money.Money `json:"max_amount"`
+}
+
+// GET /auctions/{id}/proxy-bid — свой скрытый максимум
+func (h *BidHandler) GetProxyBid(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.auctionService.GetProxyBid(auctionID, userID)
+	if err != nil {
+		writeBidError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// PUT /auctions/{id}/proxy-bid — задать или поднять максимум
+func (h *BidHandler) SetProxyBid(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	var req ProxyBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	p, err := h.auctionService.SetProxyBid(auctionID, userID, req.MaxAmount)
+	if err != nil {
+		writeBidError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, p)
+}
+
+// DELETE /auctions/{id}/proxy-bid — отменить максимум
+func (h *BidHandler) CancelProxyBid(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.auctionService.CancelProxyBid(auctionID, userID); err != nil {
+		writeBidError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// POST /auctions/{id}/buy-now — купить по buy_now_price, аукцион закрывается сразу
+func (h *BidHandler) BuyNow(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	auctionID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid auction id", http.StatusBadRequest)
+		return
+	}
+
+	a, err := h.auctionService.BuyNow(auctionID, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAuctionNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, service.ErrAuctionFinished),
+			errors.Is(err, service.ErrAuctionNotStarted),
+			errors.Is(err, service.ErrBuyNowUnavailable):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	hideReservePrice(r, a)
+	writeJSON(w, http.StatusOK, a)
+}
+
+func writeBidError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAuctionNotFound),
+		errors.Is(err, service.ErrProxyBidNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrAuctionFinished),
+		errors.Is(err, service.ErrAuctionNotStarted):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrBidRateLimited):
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+	case errors.Is(err, service.ErrBidTooLow),
+		errors.Is(err, service.ErrInvalidProxyBid):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
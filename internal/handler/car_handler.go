@@ -2,39 +2,67 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 
+	"car-store/internal/middleware"
 	"car-store/internal/model"
 	"car-store/internal/service"
 )
 
 type CarHandler struct {
-	service *service.CarService
+	service    *service.CarService
+	currencies *service.CurrencyService
 }
 
-func NewCarHandler(service *service.CarService) *CarHandler {
-	return &CarHandler{service: service}
+func NewCarHandler(service *service.CarService, currencies *service.CurrencyService) *CarHandler {
+	return &CarHandler{service: service, currencies: currencies}
 }
 
 func (h *CarHandler) CreateCar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
 	var car model.Car
 	if err := json.NewDecoder(r.Body).Decode(&car); err != nil {
 		http.Error(w, "invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	if err := h.service.CreateCar(&car); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := h.service.CreateCar(&car, adminID); err != nil {
+		writeCarError(w, err)
 		return
 	}
 
+	setETag(w, car.Version)
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(car)
 }
 func (h *CarHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 	idStr := r.URL.Query().Get("id")
 
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	isAdmin := role == "admin"
+
+	// GET /cars?archived=true — архив (только админ)
+	if r.URL.Query().Get("archived") == "true" {
+		if !isAdmin {
+			http.Error(w, "admin access required", http.StatusForbidden)
+			return
+		}
+		cars, err := h.service.GetArchivedCars()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := convertCars(h.currencies, r, cars); err != nil {
+			writeCurrencyError(w, err)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(cars)
+		return
+	}
+
 	if idStr != "" {
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
@@ -46,34 +74,294 @@ func (h *CarHandler) GetCars(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, err.Error(), 500)
 			return
 		}
-		if car == nil {
+		if car == nil || (car.DeletedAt != nil && !isAdmin) {
 			http.Error(w, "car not found", 404)
 			return
 		}
+		one := []model.Car{*car}
+		if err := convertCars(h.currencies, r, one); err != nil {
+			writeCurrencyError(w, err)
+			return
+		}
+		car = &one[0]
+		setETag(w, car.Version)
 		json.NewEncoder(w).Encode(car)
 		return
 	}
 
-	cars, _ := h.service.GetCars()
+	// GET /cars?city=Almaty — машины салонов города
+	var cars []model.Car
+	if city := r.URL.Query().Get("city"); city != "" {
+		var err error
+		if cars, err = h.service.GetCarsInCity(city); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	} else {
+		cars, _ = h.service.GetCars()
+	}
+	if err := convertCars(h.currencies, r, cars); err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
 	json.NewEncoder(w).Encode(cars)
 }
 
+// PUT /cars?id=123 — полная замена полей, If-Match необязателен
 func (h *CarHandler) UpdateCar(w http.ResponseWriter, r *http.Request) {
-	idStr := r.URL.Query().Get("id")
-	id, _ := strconv.ParseInt(idStr, 10, 64)
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	version, _, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	var c model.Car
-	json.NewDecoder(r.Body).Decode(&c)
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
 	c.ID = id
 
-	h.service.UpdateCar(&c)
-	w.WriteHeader(http.StatusOK)
+	car, err := h.service.UpdateCar(&c, version, adminID)
+	if err != nil {
+		writeCarError(w, err)
+		return
+	}
+
+	setETag(w, car.Version)
+	writeJSON(w, http.StatusOK, car)
 }
 
+// PATCH /cars?id=123 — частичное обновление, требует If-Match
+func (h *CarHandler) PatchCar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	version, present, err := ifMatchVersion(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !present {
+		http.Error(w, "If-Match header required", http.StatusPreconditionRequired)
+		return
+	}
+
+	var patch model.CarPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	car, err := h.service.PatchCar(id, patch, version, adminID)
+	if err != nil {
+		writeCarError(w, err)
+		return
+	}
+
+	setETag(w, car.Version)
+	writeJSON(w, http.StatusOK, car)
+}
+
+// DELETE /cars?id=123 — машина уходит в архив
 func (h *CarHandler) DeleteCar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
 	idStr := r.URL.Query().Get("id")
-	id, _ := strconv.ParseInt(idStr, 10, 64)
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
 
-	h.service.DeleteCar(id)
+	if err := h.service.DeleteCar(id, adminID); err != nil {
+		writeCarError(w, err)
+		return
+	}
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// POST /cars/{id}/restore
+func (h *CarHandler) RestoreCar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	car, err := h.service.RestoreCar(id, adminID)
+	if err != nil {
+		writeCarError(w, err)
+		return
+	}
+
+	setETag(w, car.Version)
+	writeJSON(w, http.StatusOK, car)
+}
+
+type ChangeCarStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// POST /cars/{id}/status
+func (h *CarHandler) ChangeStatus(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	var req ChangeCarStatusRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	car, err := h.service.ChangeStatus(id, req.Status, adminID, req.Reason)
+	if err != nil {
+		writeCarError(w, err)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(car)
+}
+
+// GET /cars/decode-vin?vin=...
+func (h *CarHandler) DecodeVIN(w http.ResponseWriter, r *http.Request) {
+	info, err := h.service.DecodeVIN(r.URL.Query().Get("vin"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(info)
+}
+
+// GET /cars/{id}/price-history
+func (h *CarHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.service.GetPriceHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(history)
+}
+
+// GET /cars/{id}/status-history
+func (h *CarHandler) GetStatusHistory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.service.GetStatusHistory(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(history)
+}
+
+type TransferCarRequest struct {
+	ShowroomID int64  `json:"showroom_id"`
+	Reason     string `json:"reason"`
+}
+
+// POST /cars/{id}/transfer
+func (h *CarHandler) TransferCar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	var req TransferCarRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	transfer, err := h.service.TransferCar(id, req.ShowroomID, adminID, req.Reason)
+	if err != nil {
+		writeCarError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, transfer)
+}
+
+// GET /cars/{id}/transfers
+func (h *CarHandler) GetTransfers(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	transfers, err := h.service.GetTransfers(id)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if transfers == nil {
+		transfers = []model.CarTransfer{}
+	}
+
+	writeJSON(w, http.StatusOK, transfers)
+}
+
+func writeCarError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound),
+		errors.Is(err, service.ErrShowroomNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrInvalidCarStatus),
+		errors.Is(err, service.ErrInvalidCar):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrVersionMismatch):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, service.ErrInvalidStatusTransition),
+		errors.Is(err, service.ErrCarStatusConflict),
+		errors.Is(err, service.ErrCarArchived),
+		errors.Is(err, service.ErrCarNotArchived),
+		errors.Is(err, service.ErrCarHasOrder),
+		errors.Is(err, service.ErrCarOnActiveAuction),
+		errors.Is(err, service.ErrCarHasTradeIn),
+		errors.Is(err, service.ErrCarHasReservation),
+		errors.Is(err, service.ErrSameShowroom):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
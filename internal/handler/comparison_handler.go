@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"car-store/internal/service"
+)
+
+type ComparisonHandler struct {
+	service    *service.ComparisonService
+	currencies *service.CurrencyService
+}
+
+func NewComparisonHandler(service *service.ComparisonService, currencies *service.CurrencyService) *ComparisonHandler {
+	return &ComparisonHandler{service: service, currencies: currencies}
+}
+
+// GET /cars/compare?ids=1,2,3
+func (h *ComparisonHandler) Compare(w http.ResponseWriter, r *http.Request) {
+	var ids []int64
+	for _, part := range strings.Split(r.URL.Query().Get("ids"), ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid car id: "+part, http.StatusBadRequest)
+			return
+		}
+		ids = append(ids, id)
+	}
+
+	result, err := h.service.CompareCars(ids)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidComparison):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrCarNotFound):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		default:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	if err := convertCars(h.currencies, r, result.Cars); err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/service"
+)
+
+type CurrencyHandler struct {
+	service *service.CurrencyService
+}
+
+func NewCurrencyHandler(service *service.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{service: service}
+}
+
+// GET /exchange-rates
+func (h *CurrencyHandler) GetRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.service.GetRates()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if rates == nil {
+		rates = []model.ExchangeRate{}
+	}
+	writeJSON(w, http.StatusOK, rates)
+}
+
+type SetExchangeRateRequest struct {
+	KZTPerUnit float64 `json:"kzt_per_unit"`
+}
+
+// PUT /admin/exchange-rates/{currency}
+func (h *CurrencyHandler) SetRate(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var req SetExchangeRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	rate, err := h.service.SetRate(r.PathValue("currency"), req.KZTPerUnit, adminID)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, rate)
+}
+
+type SetPreferredCurrencyRequest struct {
+	Currency string `json:"currency"` // пусто — показывать цены как хранятся
+}
+
+// PUT /users/me/currency
+func (h *CurrencyHandler) SetPreferredCurrency(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var req SetPreferredCurrencyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SetPreferredCurrency(userID, req.Currency); err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// displayCurrency — валюта для цен в ответе: ?currency= или из профиля пользователя.
+// Пустая строка — пересчитывать не нужно.
+func displayCurrency(cs *service.CurrencyService, r *http.Request) (string, error) {
+	if code := strings.TrimSpace(r.URL.Query().Get("currency")); code != "" {
+		code = strings.ToUpper(code)
+		ok, err := cs.IsSupported(code)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return "", fmt.Errorf("%w: %s", service.ErrUnsupportedCurrency, code)
+		}
+		return code, nil
+	}
+
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int64)
+	if !ok {
+		return "", nil
+	}
+	return cs.PreferredCurrency(userID)
+}
+
+// convertCars заполняет DisplayPrice у машин, если клиенту нужна другая валюта
+func convertCars(cs *service.CurrencyService, r *http.Request, cars []model.Car) error {
+	target, err := displayCurrency(cs, r)
+	if err != nil {
+		return err
+	}
+	return cs.ApplyDisplayPrice(cars, target)
+}
+
+func writeCurrencyError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrUnsupportedCurrency),
+		errors.Is(err, service.ErrInvalidExchangeRate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/service"
+)
+
+type DocumentHandler struct {
+	service *service.DocumentService
+}
+
+func NewDocumentHandler(service *service.DocumentService) *DocumentHandler {
+	return &DocumentHandler{service: service}
+}
+
+// viewerFrom — пользователь из контекста; без токена — аноним
+func viewerFrom(r *http.Request) service.DocumentViewer {
+	userID, _ := r.Context().Value(middleware.UserIDKey).(int64)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+	return service.DocumentViewer{UserID: userID, Role: role}
+}
+
+// POST /cars/{id}/documents — multipart/form-data: file, type, visibility
+func (h *DocumentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	// запас на заголовки multipart и текстовые поля
+	r.Body = http.MaxBytesReader(w, r.Body, service.MaxDocumentSize+1<<20)
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, service.ErrDocumentTooLarge.Error(), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "multipart form with a file field is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	doc := model.CarDocument{
+		CarID:      carID,
+		Type:       r.FormValue("type"),
+		Visibility: r.FormValue("visibility"),
+		FileName:   header.Filename,
+	}
+
+	if err := h.service.Upload(r.Context(), &doc, adminID, file); err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, doc)
+}
+
+// GET /cars/{id}/documents — только документы, доступные запросившему
+func (h *DocumentHandler) ListForCar(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	docs, err := h.service.ListForCar(carID, viewerFrom(r))
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, docs)
+}
+
+// GET /documents/{id} — скачивание
+func (h *DocumentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid document id", http.StatusBadRequest)
+		return
+	}
+
+	doc, content, err := h.service.Open(r.Context(), id, viewerFrom(r))
+	if err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	defer content.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(doc.SizeBytes, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if doc.Visibility != model.DocumentPublic {
+		w.Header().Set("Cache-Control", "private, no-store")
+	}
+
+	if _, err := io.Copy(w, content); err != nil {
+		log.Println("error sending document:", err)
+	}
+}
+
+// DELETE /documents/{id}
+func (h *DocumentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid document id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, adminID); err != nil {
+		writeDocumentError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeDocumentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrDocumentNotFound),
+		errors.Is(err, service.ErrCarNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidDocument):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrDocumentTooLarge):
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+	case errors.Is(err, service.ErrDocumentAuthRequired):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, service.ErrDocumentForbidden),
+		errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrCarArchived):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+var errBadIfMatch = errors.New("invalid If-Match header")
+
+// setETag отдаёт версию ресурса как ETag: "3"
+func setETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ifMatchVersion достаёт версию из If-Match.
+// present == false — заголовка нет; "*" — любая версия (0).
+func ifMatchVersion(r *http.Request) (version int, present bool, err error) {
+	v := strings.TrimSpace(r.Header.Get("If-Match"))
+	if v == "" {
+		return 0, false, nil
+	}
+	if v == "*" {
+		return 0, true, nil
+	}
+
+	v = strings.TrimPrefix(v, "W/")
+	v = strings.Trim(v, `"`)
+
+	version, err = strconv.Atoi(v)
+	if err != nil || version <= 0 {
+		return 0, true, errBadIfMatch
+	}
+	return version, true, nil
+}
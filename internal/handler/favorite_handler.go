@@ -10,11 +10,12 @@ import (
 )
 
 type FavoriteHandler struct {
-	service *service.FavoriteService
+	service    *service.FavoriteService
+	currencies *service.CurrencyService
 }
 
-func NewFavoriteHandler(service *service.FavoriteService) *FavoriteHandler {
-	return &FavoriteHandler{service: service}
+func NewFavoriteHandler(service *service.FavoriteService, currencies *service.CurrencyService) *FavoriteHandler {
+	return &FavoriteHandler{service: service, currencies: currencies}
 }
 
 func (h *FavoriteHandler) Add(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +66,10 @@ func (h *FavoriteHandler) GetMy(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	if err := convertCars(h.currencies, r, cars); err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
 
 	_ = json.NewEncoder(w).Encode(cars)
 }
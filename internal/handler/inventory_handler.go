@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/money"
+	"car-store/internal/service"
+)
+
+// максимальный размер загружаемого файла — 10 MB
+const maxImportSize = 10 << 20
+
+type InventoryHandler struct {
+	service *service.InventoryService
+	access  service.ShowroomAccess
+}
+
+func NewInventoryHandler(service *service.InventoryService, access service.ShowroomAccess) *InventoryHandler {
+	return &InventoryHandler{service: service, access: access}
+}
+
+// --------------------
+// ADMIN: POST /admin/cars/import?format=csv&dry_run=true&all_or_nothing=true
+// тело запроса — сам файл (CSV или JSON-массив)
+// импорт затрагивает машины всех салонов — только админ без ограничений
+// --------------------
+func (h *InventoryHandler) Import(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	global, err := h.access.CanManage(adminID, nil)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !global {
+		http.Error(w, service.ErrShowroomAccessDenied.Error(), http.StatusForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+
+	format := q.Get("format")
+	if format == "" {
+		format = formatFromContentType(r.Header.Get("Content-Type"))
+	}
+
+	opts := model.ImportOptions{
+		DryRun:       q.Get("dry_run") == "true",
+		AllOrNothing: q.Get("all_or_nothing") == "true",
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	report, err := h.service.ImportCars(body, format, opts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	if report.Invalid > 0 || report.Failed > 0 {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, report)
+}
+
+// --------------------
+// ADMIN: GET /admin/cars/export?format=csv&status=available&brand=Toyota
+// --------------------
+func (h *InventoryHandler) Export(w http.ResponseWriter, r *http.Request) {
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "csv"
+	}
+
+	filter, err := parseCarFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+	default:
+		http.Error(w, service.ErrUnsupportedFormat.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf(
+		`attachment; filename="cars-%s.%s"`, time.Now().Format("20060102"), format,
+	))
+
+	if err := h.service.ExportCars(w, format, filter); err != nil {
+		if errors.Is(err, service.ErrUnsupportedFormat) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func formatFromContentType(ct string) string {
+	switch {
+	case strings.Contains(ct, "csv"):
+		return "csv"
+	case strings.Contains(ct, "json"):
+		return "json"
+	}
+	return ""
+}
+
+func parseCarFilter(r *http.Request) (model.CarFilter, error) {
+	q := r.URL.Query()
+	f := model.CarFilter{
+		Status: q.Get("status"),
+		Brand:  q.Get("brand"),
+		City:   q.Get("city"),
+	}
+
+	var err error
+	if v := q.Get("year_from"); v != "" {
+		if f.YearFrom, err = strconv.Atoi(v); err != nil {
+			return f, errors.New("invalid year_from")
+		}
+	}
+	if v := q.Get("year_to"); v != "" {
+		if f.YearTo, err = strconv.Atoi(v); err != nil {
+			return f, errors.New("invalid year_to")
+		}
+	}
+	if v := q.Get("price_min"); v != "" {
+		if f.PriceMin, err = money.Parse(v); err != nil {
+			return f, errors.New("invalid price_min")
+		}
+	}
+	if v := q.Get("price_max"); v != "" {
+		if f.PriceMax, err = money.Parse(v); err != nil {
+			return f, errors.New("invalid price_max")
+		}
+	}
+	return f, nil
+}
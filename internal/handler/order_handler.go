@@ -31,7 +31,8 @@ func (h *OrderHandler) Buy(w http.ResponseWriter, r *http.Request) {
 		switch err {
 		case service.ErrCarNotFound:
 			http.Error(w, err.Error(), http.StatusNotFound)
-		case service.ErrCarAlreadySold:
+		case service.ErrCarAlreadySold, service.ErrCarNotForSale, service.ErrCarReserved,
+			service.ErrCarStatusConflict, service.ErrCarArchived:
 			http.Error(w, err.Error(), http.StatusConflict)
 		default:
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/service"
+)
+
+type RecommendationHandler struct {
+	service    *service.RecommendationService
+	currencies *service.CurrencyService
+}
+
+func NewRecommendationHandler(service *service.RecommendationService, currencies *service.CurrencyService) *RecommendationHandler {
+	return &RecommendationHandler{service: service, currencies: currencies}
+}
+
+// GET /cars/{id}/similar?limit=10
+func (h *RecommendationHandler) Similar(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	recs, err := h.service.SimilarCars(carID, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrCarNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeRecommendations(w, r, recs)
+}
+
+// GET /recommendations/me?limit=10
+func (h *RecommendationHandler) ForMe(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+	recs, err := h.service.ForUser(r.Context(), userID, limit)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.writeRecommendations(w, r, recs)
+}
+
+func (h *RecommendationHandler) writeRecommendations(w http.ResponseWriter, r *http.Request, recs []model.Recommendation) {
+	target, err := displayCurrency(h.currencies, r)
+	if err != nil {
+		writeCurrencyError(w, err)
+		return
+	}
+	if target != "" {
+		for i := range recs {
+			recs[i].Car.DisplayPrice, err = h.currencies.Convert(recs[i].Car.Price, recs[i].Car.Currency, target)
+			if err != nil {
+				writeCurrencyError(w, err)
+				return
+			}
+		}
+	}
+
+	writeJSON(w, http.StatusOK, recs)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"car-store/internal/middleware"
+	"car-store/internal/money"
+	"car-store/internal/service"
+)
+
+type ReservationHandler struct {
+	service *service.ReservationService
+}
+
+func NewReservationHandler(service *service.ReservationService) *ReservationHandler {
+	return &ReservationHandler{service: service}
+}
+
+type ReserveRequest struct {
+	Deposit money.Money `json:"deposit"`
+}
+
+// POST /cars/{id}/reserve
+func (h *ReservationHandler) Reserve(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	// тело необязательно, если депозит не требуется
+	var req ReserveRequest
+	// ContentLength == -1 при chunked-теле, поэтому смотрим на само тело
+	if r.Body != http.NoBody {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.service.Reserve(userID, carID, req.Deposit)
+	if err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// DELETE /cars/{id}/reserve
+func (h *ReservationHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Cancel(userID, carID); err != nil {
+		writeReservationError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /reservations/my
+func (h *ReservationHandler) GetMy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	list, err := h.service.GetMyReservations(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	_ = json.NewEncoder(w).Encode(list)
+}
+
+func writeReservationError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound),
+		errors.Is(err, service.ErrReservationNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrDepositRequired):
+		http.Error(w, err.Error(), http.StatusPaymentRequired)
+	case errors.Is(err, service.ErrCarReserved),
+		errors.Is(err, service.ErrCarNotReservable),
+		errors.Is(err, service.ErrCarArchived),
+		errors.Is(err, service.ErrCarStatusConflict):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
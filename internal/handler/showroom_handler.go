@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/service"
+)
+
+type ShowroomHandler struct {
+	service *service.ShowroomService
+}
+
+func NewShowroomHandler(service *service.ShowroomService) *ShowroomHandler {
+	return &ShowroomHandler{service: service}
+}
+
+// GET /showrooms?city=Almaty
+func (h *ShowroomHandler) GetShowrooms(w http.ResponseWriter, r *http.Request) {
+	list, err := h.service.GetShowrooms(r.URL.Query().Get("city"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []model.Showroom{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// GET /showrooms/{id}
+func (h *ShowroomHandler) GetShowroom(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid showroom id", http.StatusBadRequest)
+		return
+	}
+
+	sr, err := h.service.GetShowroom(id)
+	if err != nil {
+		writeShowroomError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, sr)
+}
+
+// POST /admin/showrooms
+func (h *ShowroomHandler) CreateShowroom(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	var sr model.Showroom
+	if err := json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.CreateShowroom(&sr, adminID); err != nil {
+		writeShowroomError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, sr)
+}
+
+// PUT /admin/showrooms/{id}
+func (h *ShowroomHandler) UpdateShowroom(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid showroom id", http.StatusBadRequest)
+		return
+	}
+
+	var sr model.Showroom
+	if err := json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	sr.ID = id
+
+	updated, err := h.service.UpdateShowroom(&sr, adminID)
+	if err != nil {
+		writeShowroomError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+type AdminScopeRequest struct {
+	ShowroomIDs []int64 `json:"showroom_ids"` // пусто — доступ ко всем салонам
+}
+
+// GET /admin/users/{id}/showrooms
+func (h *ShowroomHandler) GetAdminScope(w http.ResponseWriter, r *http.Request) {
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	ids, err := h.service.GetAdminScope(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, http.StatusOK, AdminScopeRequest{ShowroomIDs: ids})
+}
+
+// PUT /admin/users/{id}/showrooms
+func (h *ShowroomHandler) SetAdminScope(w http.ResponseWriter, r *http.Request) {
+	actorID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	userID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	var req AdminScopeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.SetAdminScope(actorID, userID, req.ShowroomIDs); err != nil {
+		writeShowroomError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeShowroomError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrShowroomNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidShowroom),
+		errors.Is(err, service.ErrUserNotAdmin):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"car-store/internal/middleware"
+	"car-store/internal/model"
+	"car-store/internal/service"
+)
+
+type TestDriveHandler struct {
+	service *service.TestDriveService
+}
+
+func NewTestDriveHandler(service *service.TestDriveService) *TestDriveHandler {
+	return &TestDriveHandler{service: service}
+}
+
+type TestDriveRequest struct {
+	StartsAt time.Time `json:"starts_at"` // RFC 3339, например "2026-10-20T11:00:00+05:00"
+}
+
+// GET /cars/{id}/test-drives?date=2026-10-20
+func (h *TestDriveHandler) GetSlots(w http.ResponseWriter, r *http.Request) {
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	date := r.URL.Query().Get("date")
+	if date == "" {
+		http.Error(w, "date is required", http.StatusBadRequest)
+		return
+	}
+
+	slots, err := h.service.Slots(carID, date)
+	if err != nil {
+		writeTestDriveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, slots)
+}
+
+// POST /cars/{id}/test-drives
+func (h *TestDriveHandler) Book(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	carID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid car id", http.StatusBadRequest)
+		return
+	}
+
+	var req TestDriveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StartsAt.IsZero() {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	td, err := h.service.Book(userID, carID, req.StartsAt)
+	if err != nil {
+		writeTestDriveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, td)
+}
+
+// PATCH /test-drives/{id} — перенос на другой слот
+func (h *TestDriveHandler) Reschedule(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid test drive id", http.StatusBadRequest)
+		return
+	}
+
+	var req TestDriveRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.StartsAt.IsZero() {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	td, err := h.service.Reschedule(id, userID, req.StartsAt)
+	if err != nil {
+		writeTestDriveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, td)
+}
+
+// DELETE /test-drives/{id}
+func (h *TestDriveHandler) Cancel(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+	role, _ := r.Context().Value(middleware.RoleKey).(string)
+
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "invalid test drive id", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.Cancel(id, userID, role == "admin"); err != nil {
+		writeTestDriveError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GET /test-drives/my
+func (h *TestDriveHandler) GetMy(w http.ResponseWriter, r *http.Request) {
+	userID := r.Context().Value(middleware.UserIDKey).(int64)
+
+	list, err := h.service.GetMy(userID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if list == nil {
+		list = []model.TestDrive{}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// ADMIN: GET /admin/test-drives?from=2026-10-20&to=2026-10-26&showroom_id=1
+func (h *TestDriveHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	adminID := r.Context().Value(middleware.UserIDKey).(int64)
+	q := r.URL.Query()
+
+	var showroomID *int64
+	if v := q.Get("showroom_id"); v != "" {
+		id, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid showroom_id", http.StatusBadRequest)
+			return
+		}
+		showroomID = &id
+	}
+
+	days, err := h.service.Calendar(adminID, q.Get("from"), q.Get("to"), showroomID)
+	if err != nil {
+		writeTestDriveError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, days)
+}
+
+func writeTestDriveError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCarNotFound),
+		errors.Is(err, service.ErrTestDriveNotFound),
+		errors.Is(err, service.ErrShowroomNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrInvalidTestDrive):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrShowroomAccessDenied):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrTestDriveSlotTaken),
+		errors.Is(err, service.ErrTestDriveOverlap),
+		errors.Is(err, service.ErrCarNotTestDrivable),
+		errors.Is(err, service.ErrTestDriveClosed):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -3,6 +3,7 @@ package middleware
 import (
 	"context"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -17,6 +18,9 @@ const (
 	RoleKey   ctxKey = "role"
 )
 
+// назначение короткоживущего токена потока аукциона (AuthService.IssueStreamToken)
+const streamTokenPurpose = "auction_stream"
+
 // --------------------
 // AUTH (JWT)
 // --------------------
@@ -28,25 +32,86 @@ func Auth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
+		// токен потока как обычный не принимается
+		claims, ok := parseToken(strings.TrimPrefix(authHeader, "Bearer "), "")
+		if !ok {
+			http.Error(w, "invalid token", http.StatusUnauthorized)
+			return
+		}
+		serve(w, r, claims, next)
+	}
+}
 
-		token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
-			return jwtSecret, nil
-		})
-		if err != nil || !token.Valid {
+// --------------------
+// STREAM AUTH
+// --------------------
+// StreamAuth — как Auth, но EventSource и WebSocket в браузере не умеют ставить
+// заголовок Authorization, поэтому можно передать ?stream_token= — токен
+// на поток одного аукциона, живущий минуту (POST /auctions/{id}/stream-token).
+// Основной токен в URL не принимается: URL попадают в логи сервера и прокси.
+func StreamAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			Auth(next)(w, r)
+			return
+		}
+
+		tokenStr := r.URL.Query().Get("stream_token")
+		if tokenStr == "" {
+			http.Error(w, "missing token", http.StatusUnauthorized)
+			return
+		}
+		claims, ok := parseToken(tokenStr, streamTokenPurpose)
+		if !ok {
 			http.Error(w, "invalid token", http.StatusUnauthorized)
 			return
 		}
+		auctionID, _ := claims["auction_id"].(float64)
+		if strconv.FormatInt(int64(auctionID), 10) != r.PathValue("id") {
+			http.Error(w, "token is not valid for this auction", http.StatusForbidden)
+			return
+		}
+		serve(w, r, claims, next)
+	}
+}
+
+// parseToken проверяет подпись и срок токена и что он выписан для purpose
+// ("" — обычный токен входа)
+func parseToken(tokenStr, purpose string) (jwt.MapClaims, bool) {
+	token, err := jwt.Parse(tokenStr, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, false
+	}
+
+	claims := token.Claims.(jwt.MapClaims)
+	p, _ := claims["purpose"].(string)
+	return claims, p == purpose
+}
 
-		claims := token.Claims.(jwt.MapClaims)
+func serve(w http.ResponseWriter, r *http.Request, claims jwt.MapClaims, next http.HandlerFunc) {
+	userID := int64(claims["user_id"].(float64))
+	role := claims["role"].(string)
 
-		userID := int64(claims["user_id"].(float64))
-		role := claims["role"].(string)
+	ctx := context.WithValue(r.Context(), UserIDKey, userID)
+	ctx = context.WithValue(ctx, RoleKey, role)
 
-		ctx := context.WithValue(r.Context(), UserIDKey, userID)
-		ctx = context.WithValue(ctx, RoleKey, role)
+	next(w, r.WithContext(ctx))
+}
 
-		next(w, r.WithContext(ctx))
+// --------------------
+// OPTIONAL AUTH
+// --------------------
+// OptionalAuth пропускает запрос без токена как анонимный (в контексте
+// нет UserIDKey), а с токеном — проверяет его так же, как Auth.
+func OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			next(w, r)
+			return
+		}
+		Auth(next)(w, r)
 	}
 }
 
@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func signToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	s, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(jwtSecret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestStreamAuth(t *testing.T) {
+	exp := time.Now().Add(time.Minute).Unix()
+	login := signToken(t, jwt.MapClaims{"user_id": 7, "role": "user", "exp": exp})
+	stream := signToken(t, jwt.MapClaims{
+		"user_id": 7, "role": "user", "exp": exp,
+		"purpose": streamTokenPurpose, "auction_id": 42,
+	})
+	expired := signToken(t, jwt.MapClaims{
+		"user_id": 7, "role": "user", "exp": time.Now().Add(-time.Second).Unix(),
+		"purpose": streamTokenPurpose, "auction_id": 42,
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/auctions/{id}/stream", StreamAuth(func(w http.ResponseWriter, r *http.Request) {
+		if r.Context().Value(UserIDKey).(int64) != 7 {
+			t.Error("user id not set")
+		}
+	}))
+	mux.HandleFunc("/me", Auth(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		url    string
+		bearer string
+		want   int
+	}{
+		{"login token in header", "/auctions/42/stream", login, http.StatusOK},
+		{"stream token in query", "/auctions/42/stream?stream_token=" + stream, "", http.StatusOK},
+		{"stream token for other auction", "/auctions/43/stream?stream_token=" + stream, "", http.StatusForbidden},
+		{"expired stream token", "/auctions/42/stream?stream_token=" + expired, "", http.StatusUnauthorized},
+		{"login token in query", "/auctions/42/stream?stream_token=" + login, "", http.StatusUnauthorized},
+		{"legacy access_token param", "/auctions/42/stream?access_token=" + login, "", http.StatusUnauthorized},
+		{"no token", "/auctions/42/stream", "", http.StatusUnauthorized},
+		{"stream token as bearer", "/me", stream, http.StatusUnauthorized},
+		{"stream token as bearer on stream", "/auctions/42/stream", stream, http.StatusUnauthorized},
+		{"login token as bearer", "/me", login, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.bearer != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.bearer)
+			}
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
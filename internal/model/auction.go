@@ -1,14 +1,137 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"car-store/internal/money"
+)
 
 type Auction struct {
-	ID           int64     `json:"id"`
-	CarID        int64     `json:"car_id"`
-	StartPrice   float64   `json:"start_price"`
-	StartTime    time.Time `json:"start_time"`
-	EndTime      time.Time `json:"end_time"`
-	CreatedAt    time.Time `json:"created_at"`
-	CurrentPrice float64   `json:"current_price"`
-	BidCount     int       `json:"bid_count"`
+	ID               int64        `json:"id"`
+	CarID            int64        `json:"car_id"`
+	StartPrice       money.Money  `json:"start_price"`
+	ReservePrice     *money.Money `json:"reserve_price,omitempty"` // скрыта от всех, кроме админов
+	BuyNowPrice      *money.Money `json:"buy_now_price,omitempty"` // цена, по которой машину можно купить сразу
+	StartTime        time.Time    `json:"start_time"`
+	EndTime          time.Time    `json:"end_time"`
+	ScheduledEndTime time.Time    `json:"scheduled_end_time"` // EndTime позже, если аукцион продлён ставками
+	Status           string       `json:"status"`
+	SettledAt        *time.Time   `json:"settled_at,omitempty"`
+	CreatedAt        time.Time    `json:"created_at"`
+	CurrentPrice     money.Money  `json:"current_price"`
+	BidCount         int          `json:"bid_count"`
+	ReserveMet       *bool        `json:"reserve_met,omitempty"` // nil — резервной цены нет
+	NextMinBid       money.Money  `json:"next_min_bid"`
+	BuyNowAvailable  bool         `json:"buy_now_available"`
+	StartsIn         int64        `json:"starts_in,omitempty"` // секунд до начала, только для scheduled
+	Version          int          `json:"version"`
+}
+
+// статусы аукциона
+const (
+	AuctionStatusScheduled = "scheduled" // ещё не начался
+	AuctionStatusActive    = "active"
+	AuctionStatusEnded     = "ended"     // время вышло, идёт расчёт
+	AuctionStatusSettled   = "settled"   // продан, заказ создан
+	AuctionStatusCancelled = "cancelled" // отменён админом
+	AuctionStatusNoSale    = "no_sale"   // без ставок или ниже резерва
+)
+
+// AuctionOpen — аукцион в этом статусе ещё принимает ставки и изменения,
+// если не вышло время
+func AuctionOpen(status string) bool {
+	return status == AuctionStatusScheduled || status == AuctionStatusActive
+}
+
+// SoftClose — продление аукциона ставками в последние минуты (anti-sniping).
+// Ставка, принятая позже чем за Window до конца, отодвигает конец на Extension
+// от момента ставки, но не дальше ScheduledEndTime + MaxExtension.
+type SoftClose struct {
+	Window       time.Duration // 0 — продление выключено
+	Extension    time.Duration
+	MaxExtension time.Duration // 0 — без ограничения
+}
+
+// BidIncrement — минимальный шаг ставки, пока текущая цена ниже Below.
+// Below == 0 — для всех цен выше предыдущих ступеней.
+type BidIncrement struct {
+	Below money.Money
+	Step  money.Money
+}
+
+// MinIncrement — шаг ставки для текущей цены по таблице ступеней
+func MinIncrement(table []BidIncrement, price money.Money) money.Money {
+	for _, inc := range table {
+		if inc.Below == 0 || price < inc.Below {
+			return inc.Step
+		}
+	}
+	// цена выше всех ступеней — шаг последней
+	if len(table) > 0 {
+		return table[len(table)-1].Step
+	}
+	return money.FromMinor(1)
+}
+
+// NextMinBid — минимальная допустимая ставка: без ставок это стартовая цена
+// (при нулевой — первый шаг), дальше — текущая цена плюс шаг, но не ниже стартовой
+func NextMinBid(table []BidIncrement, startPrice, currentPrice money.Money, bidCount int) money.Money {
+	if bidCount == 0 {
+		if startPrice > 0 {
+			return startPrice
+		}
+		return MinIncrement(table, 0)
+	}
+	next := currentPrice + MinIncrement(table, currentPrice)
+	if next < startPrice {
+		return startPrice
+	}
+	return next
+}
+
+// BuyNowOpen — можно ли ещё купить по buy_now_price: до первой ставки всегда,
+// дальше — пока лидирующая ставка ниже cutoffPercent процентов от неё
+func BuyNowOpen(buyNow *money.Money, currentPrice money.Money, bidCount int, cutoffPercent int) bool {
+	if buyNow == nil {
+		return false
+	}
+	if bidCount == 0 {
+		return true
+	}
+	return currentPrice < *buyNow && currentPrice*100 < *buyNow*money.Money(cutoffPercent)
+}
+
+// типы обновлений в потоке аукциона /auctions/{id}/stream
+const (
+	AuctionUpdateSnapshot  = "snapshot" // состояние на момент подключения
+	AuctionUpdateBid       = "bid"
+	AuctionUpdatePrice     = "price_changed"
+	AuctionUpdateEndTime   = "end_time_changed"
+	AuctionUpdateFinalized = "finalized"
+	AuctionUpdateDeleted   = "deleted"
+)
+
+// итог завершённого аукциона
+const (
+	AuctionResultSold   = "sold"
+	AuctionResultNoBids = "no_bids"
+	// ставки были, но до резервной цены не дошли: заказа нет, машина снова в продаже
+	AuctionResultReserveNotMet = "reserve_not_met"
+	// машину продали в обход аукциона: заказа победителя нет
+	AuctionResultCarSold = "car_sold"
+)
+
+// AuctionUpdate — событие для подписчиков аукциона; кроме типа несёт
+// актуальное состояние, чтобы клиенту не нужно было перечитывать аукцион
+type AuctionUpdate struct {
+	Type            string      `json:"type"`
+	AuctionID       int64       `json:"auction_id"`
+	CurrentPrice    money.Money `json:"current_price"`
+	BidCount        int         `json:"bid_count"`
+	ReserveMet      *bool       `json:"reserve_met,omitempty"`
+	NextMinBid      money.Money `json:"next_min_bid"`
+	BuyNowAvailable bool        `json:"buy_now_available"`
+	EndTime         time.Time   `json:"end_time"`
+	Result          string      `json:"result,omitempty"` // только для finalized
+	At              time.Time   `json:"at"`
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+const (
+	AuctionOfferPending  = "pending"
+	AuctionOfferAccepted = "accepted" // по предложению создан заказ
+	AuctionOfferRejected = "rejected"
+)
+
+// AuctionOffer — предложение цены от лидера аукциона, который закрылся,
+// не дойдя до резервной цены
+type AuctionOffer struct {
+	ID        int64       `json:"id"`
+	AuctionID int64       `json:"auction_id"`
+	UserID    int64       `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	Status    string      `json:"status"`
+	DecidedBy *int64      `json:"decided_by,omitempty"`
+	DecidedAt *time.Time  `json:"decided_at,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"testing"
+
+	"car-store/internal/money"
+)
+
+// 0..5000 — шаг 50, 5000..20000 — шаг 100, дальше — 250
+var testIncrements = []BidIncrement{
+	{Below: 500000, Step: 5000},
+	{Below: 2000000, Step: 10000},
+	{Step: 25000},
+}
+
+func TestMinIncrement(t *testing.T) {
+	tests := []struct {
+		table []BidIncrement
+		price money.Money
+		want  money.Money
+	}{
+		{testIncrements, 0, 5000},
+		{testIncrements, 499999, 5000},
+		{testIncrements, 500000, 10000}, // цена на границе — уже следующая ступень
+		{testIncrements, 1999999, 10000},
+		{testIncrements, 2000000, 25000},
+		{testIncrements, 99999999, 25000},
+		// без "*" цена выше всех ступеней берёт шаг последней
+		{testIncrements[:2], 2000000, 10000},
+		{nil, 123, 1},
+	}
+	for _, tt := range tests {
+		if got := MinIncrement(tt.table, tt.price); got != tt.want {
+			t.Errorf("MinIncrement(%d tiers, %d) = %d, want %d", len(tt.table), tt.price, got, tt.want)
+		}
+	}
+}
+
+func TestNextMinBid(t *testing.T) {
+	tests := []struct {
+		name                     string
+		startPrice, currentPrice money.Money
+		bidCount                 int
+		want                     money.Money
+	}{
+		{"первая ставка — ровно стартовая цена", 300000, 0, 0, 300000},
+		{"первая ставка при нулевой стартовой — первый шаг", 0, 0, 0, 5000},
+		{"текущая цена плюс шаг ступени", 300000, 300000, 1, 305000},
+		{"текущая цена на границе ступени", 300000, 500000, 3, 510000},
+		{"не ниже стартовой цены", 800000, 100000, 1, 800000},
+	}
+	for _, tt := range tests {
+		got := NextMinBid(testIncrements, tt.startPrice, tt.currentPrice, tt.bidCount)
+		if got != tt.want {
+			t.Errorf("%s: NextMinBid = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuyNowOpen(t *testing.T) {
+	buyNow := money.Money(1000000)
+
+	tests := []struct {
+		name         string
+		buyNow       *money.Money
+		currentPrice money.Money
+		bidCount     int
+		cutoff       int
+		want         bool
+	}{
+		{"без buy_now_price", nil, 0, 0, 50, false},
+		{"без ставок — всегда", &buyNow, 0, 0, 50, true},
+		{"без ставок при cutoff 0", &buyNow, 0, 0, 0, true},
+		{"cutoff 0 закрывает после первой ставки", &buyNow, 100000, 1, 0, false},
+		{"ниже порога", &buyNow, 499999, 2, 50, true},
+		{"ровно 50% — уже закрыто", &buyNow, 500000, 2, 50, false},
+		{"выше порога", &buyNow, 600000, 3, 50, false},
+		{"cutoff 100 — до самой цены buy now", &buyNow, 999999, 5, 100, true},
+		{"ставка дошла до buy now", &buyNow, 1000000, 5, 100, false},
+	}
+	for _, tt := range tests {
+		got := BuyNowOpen(tt.buyNow, tt.currentPrice, tt.bidCount, tt.cutoff)
+		if got != tt.want {
+			t.Errorf("%s: BuyNowOpen = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
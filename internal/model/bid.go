@@ -1,11 +1,67 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"car-store/internal/money"
+)
 
 type Bid struct {
-	ID        int64     `json:"id"`
-	AuctionID int64     `json:"auction_id"`
-	UserID    int64     `json:"user_id"`
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	ID        int64       `json:"id"`
+	AuctionID int64       `json:"auction_id"`
+	UserID    int64       `json:"user_id"`
+	Amount    money.Money `json:"amount"`
+	IsAuto    bool        `json:"is_auto"` // поставлена системой по ProxyBid пользователя
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// BidHistoryItem — ставка в истории аукциона. Bidder — псевдоним «Bidder N»,
+// постоянный в пределах аукциона: N — порядок первой ставки участника.
+// Кто стоит за псевдонимом, видят только админы и сам участник.
+type BidHistoryItem struct {
+	ID        int64       `json:"id"`
+	Bidder    string      `json:"bidder"`
+	BidderNo  int         `json:"-"`
+	UserID    *int64      `json:"user_id,omitempty"`
+	UserEmail *string     `json:"user_email,omitempty"` // только для админов
+	Mine      bool        `json:"mine"`
+	Amount    money.Money `json:"amount"`
+	IsAuto    bool        `json:"is_auto"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// ProxyBid — скрытый максимум пользователя по аукциону: когда его перебивают,
+// система сама ставит за него минимальным шагом, пока хватает максимума
+type ProxyBid struct {
+	AuctionID int64       `json:"auction_id"`
+	UserID    int64       `json:"user_id"`
+	MaxAmount money.Money `json:"max_amount"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// BidRules — правила, которые проверяются под блокировкой аукциона
+// вместе с записью ставки
+type BidRules struct {
+	SoftClose        SoftClose
+	Increments       []BidIncrement
+	MaxBidsPerMinute int // ручных ставок одного пользователя на аукцион, 0 — без лимита
+}
+
+// итог попытки записать ставку или максимум
+const (
+	BidAccepted       = "accepted"
+	BidAuctionClosed  = "auction_closed" // аукцион закончился или отменён
+	BidNotStarted     = "not_started"
+	BidTooLow         = "too_low" // ниже MinBid
+	BidRateLimited    = "rate_limited"
+	BidProxyNotRaised = "proxy_not_raised" // новый максимум не выше прежнего
+)
+
+// BidResult — итог записи под блокировкой: отказ определяется состоянием
+// аукциона на момент блокировки, а не на момент чтения до неё
+type BidResult struct {
+	Status  string
+	EndTime time.Time   // актуальный конец аукциона
+	MinBid  money.Money // для BidTooLow и BidProxyNotRaised — нижняя граница
 }
@@ -1,14 +1,40 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"car-store/internal/money"
+)
 
 type Car struct {
-	ID            int64     `json:"id"`
-	Brand         string    `json:"brand"`
-	Model         string    `json:"model"`
-	Year          int       `json:"year"`
-	Price         float64   `json:"price"`
-	Status        string    `json:"status"`
-	IsAuctionOnly bool      `json:"is_auction_only"`
-	CreatedAt     time.Time `json:"created_at"`
+	ID            int64       `json:"id"`
+	Brand         string      `json:"brand"`
+	Model         string      `json:"model"`
+	Year          int         `json:"year"`
+	Price         money.Money `json:"price"`
+	Currency      string      `json:"currency"` // ISO 4217: KZT, USD, RUB
+	ShowroomID    *int64      `json:"showroom_id,omitempty"`
+	Status        string      `json:"status"`
+	IsAuctionOnly bool        `json:"is_auction_only"`
+	VIN           string      `json:"vin,omitempty"`
+	ExternalID    string      `json:"external_id,omitempty"` // id машины во внешней системе учёта
+	Country       string      `json:"country,omitempty"`     // страна производства
+	BodyType      string      `json:"body_type,omitempty"`   // sedan, suv, hatchback, ...
+	Version       int         `json:"version"`               // растёт при каждом изменении, отдаётся как ETag
+	CreatedAt     time.Time   `json:"created_at"`
+	DeletedAt     *time.Time  `json:"deleted_at,omitempty"` // машина в архиве, если не nil
+
+	// расхождения введённых данных с VIN — не хранится, только в ответе
+	VINMismatches []string `json:"vin_mismatches,omitempty"`
+
+	// цена в валюте, запрошенной клиентом — не хранится, только в ответе
+	DisplayPrice *ConvertedPrice `json:"display_price,omitempty"`
+}
+
+// CarArchiveBlockers — ссылки, из-за которых машину нельзя убрать в архив
+type CarArchiveBlockers struct {
+	HasOrder             bool
+	HasActiveAuction     bool
+	HasAcceptedTradeIn   bool
+	HasActiveReservation bool
 }
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+const (
+	CarStatusAvailable = "available"
+	CarStatusReserved  = "reserved"
+	CarStatusOnAuction = "on_auction"
+	CarStatusSold      = "sold"
+	CarStatusWithdrawn = "withdrawn"
+)
+
+type CarStatusChange struct {
+	ID         int64     `json:"id"`
+	CarID      int64     `json:"car_id"`
+	FromStatus string    `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *int64    `json:"changed_by,omitempty"` // nil — изменено системой
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
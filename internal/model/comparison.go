@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+// CarAuctionSummary — последний аукцион машины
+type CarAuctionSummary struct {
+	AuctionID    int64       `json:"auction_id"`
+	Status       string      `json:"status"` // AuctionStatus*, кроме cancelled
+	CurrentPrice money.Money `json:"current_price"`
+	BidCount     int         `json:"bid_count"`
+	StartTime    time.Time   `json:"start_time"`
+	EndTime      time.Time   `json:"end_time"`
+}
+
+// ComparisonRow — одна характеристика по всем машинам, в порядке Cars
+type ComparisonRow struct {
+	Field   string        `json:"field"`
+	Values  []interface{} `json:"values"`
+	Differs bool          `json:"differs"`
+}
+
+type CarComparisonMetrics struct {
+	CarID             int64              `json:"car_id"`
+	AgeYears          int                `json:"age_years"`
+	PricePerYearOfAge money.Money        `json:"price_per_year_of_age"`
+	PriceVsAverage    float64            `json:"price_vs_average_pct"` // + дороже среднего, - дешевле
+	PricePosition     string             `json:"price_position"`       // below_average, average, above_average
+	FavoriteCount     int                `json:"favorite_count"`
+	Auction           *CarAuctionSummary `json:"auction,omitempty"`
+}
+
+type CarComparison struct {
+	Cars            []Car                  `json:"cars"`
+	Matrix          []ComparisonRow        `json:"matrix"`
+	Metrics         []CarComparisonMetrics `json:"metrics"`
+	CatalogAvgPrice money.Money            `json:"catalog_avg_price"` // в тенге
+}
@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+const (
+	CurrencyKZT = "KZT"
+	CurrencyUSD = "USD"
+	CurrencyRUB = "RUB"
+
+	// валюта цены, если при создании машины она не указана
+	DefaultCurrency = CurrencyUSD
+)
+
+// ExchangeRate — сколько тенге стоит одна единица валюты.
+// Курс KZT всегда 1, остальные курсы задаёт админ.
+type ExchangeRate struct {
+	Currency   string    `json:"currency"`
+	KZTPerUnit float64   `json:"kzt_per_unit"`
+	UpdatedBy  *int64    `json:"updated_by,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ConvertedPrice struct {
+	Amount   money.Money `json:"amount"`
+	Currency string      `json:"currency"`
+	Rate     float64     `json:"rate"` // сколько единиц целевой валюты за единицу исходной
+}
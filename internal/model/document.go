@@ -0,0 +1,30 @@
+package model
+
+import "time"
+
+// типы документов машины
+const (
+	DocumentInspection    = "inspection"
+	DocumentServiceRecord = "service_record"
+	DocumentRegistration  = "registration"
+)
+
+// кто может видеть документ
+const (
+	DocumentPublic     = "public"
+	DocumentBuyersOnly = "buyers"     // покупатель машины или тот, кто её забронировал
+	DocumentAdminOnly  = "admin_only" // только админы
+)
+
+type CarDocument struct {
+	ID          int64     `json:"id"`
+	CarID       int64     `json:"car_id"`
+	Type        string    `json:"type"`
+	Visibility  string    `json:"visibility"`
+	FileName    string    `json:"file_name"`
+	ContentType string    `json:"content_type"`
+	SizeBytes   int64     `json:"size_bytes"`
+	StorageKey  string    `json:"-"`
+	UploadedBy  *int64    `json:"uploaded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
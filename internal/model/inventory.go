@@ -0,0 +1,61 @@
+package model
+
+import "car-store/internal/money"
+
+// CarFilter — фильтры для выборки машин (экспорт, каталог).
+// Пустые/нулевые поля не участвуют в фильтрации.
+type CarFilter struct {
+	Status   string
+	Brand    string
+	YearFrom int
+	YearTo   int
+	PriceMin money.Money
+	PriceMax money.Money
+	City     string // город салона, без учёта регистра
+
+	IncludeArchived bool
+}
+
+type ImportOptions struct {
+	DryRun       bool `json:"dry_run"`
+	AllOrNothing bool `json:"all_or_nothing"` // любая ошибка — откат всего импорта
+}
+
+// ImportRow — одна строка входного файла после разбора
+type ImportRow struct {
+	Line   int
+	Car    Car
+	Errors []string
+}
+
+// CarUpsertResult — результат записи одной машины в БД
+type CarUpsertResult struct {
+	CarID  int64
+	Action string // created, updated, failed, skipped
+	Err    error
+
+	PriceChanged bool
+	OldPrice     money.Money
+	OldCurrency  string
+}
+
+type ImportRowResult struct {
+	Line       int      `json:"line"`
+	VIN        string   `json:"vin,omitempty"`
+	ExternalID string   `json:"external_id,omitempty"`
+	CarID      int64    `json:"car_id,omitempty"`
+	Action     string   `json:"action"` // created, updated, invalid, failed, skipped
+	Errors     []string `json:"errors,omitempty"`
+	Warnings   []string `json:"warnings,omitempty"` // расхождения с VIN
+}
+
+type ImportReport struct {
+	DryRun    bool              `json:"dry_run"`
+	Committed bool              `json:"committed"`
+	Total     int               `json:"total"`
+	Created   int               `json:"created"`
+	Updated   int               `json:"updated"`
+	Invalid   int               `json:"invalid"`
+	Failed    int               `json:"failed"`
+	Rows      []ImportRowResult `json:"rows"`
+}
@@ -1,12 +1,17 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"car-store/internal/money"
+)
 
 type Order struct {
-	ID         int64     `json:"id"`
-	UserID     int64     `json:"user_id"`
-	CarID      int64     `json:"car_id"`
-	TotalPrice float64   `json:"total_price"`
-	Source     string    `json:"source"` // 'direct' | 'auction'
-	CreatedAt  time.Time `json:"created_at"`
+	ID         int64       `json:"id"`
+	UserID     int64       `json:"user_id"`
+	CarID      int64       `json:"car_id"`
+	TotalPrice money.Money `json:"total_price"`
+	Currency   string      `json:"currency"`
+	Source     string      `json:"source"` // 'direct' | 'auction'
+	CreatedAt  time.Time   `json:"created_at"`
 }
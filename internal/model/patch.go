@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+// CarPatch — частичное обновление машины: nil-поля не меняются
+type CarPatch struct {
+	Brand         *string      `json:"brand"`
+	Model         *string      `json:"model"`
+	Year          *int         `json:"year"`
+	Price         *money.Money `json:"price"`
+	Currency      *string      `json:"currency"`
+	Status        *string      `json:"status"`
+	IsAuctionOnly *bool        `json:"is_auction_only"`
+	VIN           *string      `json:"vin"`
+	ExternalID    *string      `json:"external_id"`
+	Country       *string      `json:"country"`
+	BodyType      *string      `json:"body_type"`
+}
+
+// AuctionPatch — частичное обновление аукциона. Машину у аукциона сменить нельзя.
+type AuctionPatch struct {
+	StartPrice   *money.Money `json:"start_price"`
+	ReservePrice *money.Money `json:"reserve_price"` // 0 — убрать резервную цену
+	BuyNowPrice  *money.Money `json:"buy_now_price"` // 0 — убрать «купить сейчас»
+	StartTime    *time.Time   `json:"start_time"`
+	EndTime      *time.Time   `json:"end_time"`
+}
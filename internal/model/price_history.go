@@ -0,0 +1,18 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+type CarPriceChange struct {
+	ID          int64       `json:"id"`
+	CarID       int64       `json:"car_id"`
+	OldPrice    money.Money `json:"old_price"`
+	OldCurrency string      `json:"old_currency"`
+	NewPrice    money.Money `json:"new_price"`
+	NewCurrency string      `json:"new_currency"`
+	ChangedBy   *int64      `json:"changed_by,omitempty"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
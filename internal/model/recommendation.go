@@ -0,0 +1,7 @@
+package model
+
+type Recommendation struct {
+	Car    Car     `json:"car"`
+	Score  float64 `json:"score"`            // 0..1
+	Reason string  `json:"reason,omitempty"` // почему предложили
+}
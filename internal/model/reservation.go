@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"car-store/internal/money"
+)
+
+const (
+	ReservationActive    = "active"
+	ReservationCancelled = "cancelled"
+	ReservationExpired   = "expired"
+	ReservationCompleted = "completed" // машина куплена
+)
+
+type Reservation struct {
+	ID        int64       `json:"id"`
+	CarID     int64       `json:"car_id"`
+	UserID    int64       `json:"user_id"`
+	Deposit   money.Money `json:"deposit"`
+	Status    string      `json:"status"`
+	ExpiresAt time.Time   `json:"expires_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
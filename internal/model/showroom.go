@@ -0,0 +1,32 @@
+package model
+
+import "time"
+
+// ShowroomHours — часы работы в один день недели, время "HH:MM" по местному времени
+type ShowroomHours struct {
+	Weekday time.Weekday `json:"weekday"` // 0 — воскресенье
+	Opens   string       `json:"opens"`
+	Closes  string       `json:"closes"`
+}
+
+type Showroom struct {
+	ID           int64           `json:"id"`
+	Name         string          `json:"name"`
+	City         string          `json:"city"`
+	Address      string          `json:"address"`
+	Latitude     float64         `json:"latitude"`
+	Longitude    float64         `json:"longitude"`
+	WorkingHours []ShowroomHours `json:"working_hours"` // дней нет в списке — выходной
+	CreatedAt    time.Time       `json:"created_at"`
+}
+
+// CarTransfer — перемещение машины между салонами
+type CarTransfer struct {
+	ID             int64     `json:"id"`
+	CarID          int64     `json:"car_id"`
+	FromShowroomID *int64    `json:"from_showroom_id,omitempty"` // nil — машина не была закреплена
+	ToShowroomID   int64     `json:"to_showroom_id"`
+	TransferredBy  int64     `json:"transferred_by"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,35 @@
+package model
+
+import "time"
+
+const (
+	TestDriveBooked    = "booked"
+	TestDriveCancelled = "cancelled"
+	TestDriveRejected  = "rejected" // машину продали или выставили на аукцион
+)
+
+type TestDrive struct {
+	ID         int64     `json:"id"`
+	CarID      int64     `json:"car_id"`
+	UserID     int64     `json:"user_id"`
+	ShowroomID int64     `json:"showroom_id"`
+	StartsAt   time.Time `json:"starts_at"`
+	EndsAt     time.Time `json:"ends_at"`
+	Status     string    `json:"status"`
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// TestDriveSlot — окно для тест-драйва в часы работы салона
+type TestDriveSlot struct {
+	StartsAt  time.Time `json:"starts_at"`
+	EndsAt    time.Time `json:"ends_at"`
+	Available bool      `json:"available"`
+}
+
+// TestDriveCalendarDay — записи на тест-драйв за один день, для календаря админа
+type TestDriveCalendarDay struct {
+	Date       string      `json:"date"` // YYYY-MM-DD по местному времени
+	TestDrives []TestDrive `json:"test_drives"`
+}
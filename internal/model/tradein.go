@@ -1,21 +1,25 @@
 package model
 
-import "time"
+import (
+	"time"
+
+	"car-store/internal/money"
+)
 
 type TradeIn struct {
-	ID              int64     `json:"id"`
-	UserID          int64     `json:"user_id"`
-	OfferedBrand    string    `json:"offered_brand"` // Марка старой машины юзера
-	OfferedModel    string    `json:"offered_model"` // Модель старой машины юзера
-	Year            int       `json:"year"`
-	Mileage         int       `json:"mileage"`
-	DesiredCarID    *int64    `json:"desired_car_id,omitempty"`    // ID машины которую хочет купить
-	EstimatedPrice  *float64  `json:"estimated_price,omitempty"`   // Оценка админа за старую машину
-	Status          string    `json:"status"`                      // pending, evaluated, accepted, rejected
-	UserPayment     *float64  `json:"user_payment,omitempty"`      // Сколько юзер готов доплатить
-	KolesaSearchURL string    `json:"kolesa_search_url,omitempty"` // Ссылка на kolesa.kz
-	CreatedAt       time.Time `json:"created_at"`
-	UpdatedAt       time.Time `json:"updated_at"`
+	ID              int64        `json:"id"`
+	UserID          int64        `json:"user_id"`
+	OfferedBrand    string       `json:"offered_brand"` // Марка старой машины юзера
+	OfferedModel    string       `json:"offered_model"` // Модель старой машины юзера
+	Year            int          `json:"year"`
+	Mileage         int          `json:"mileage"`
+	DesiredCarID    *int64       `json:"desired_car_id,omitempty"`    // ID машины которую хочет купить
+	EstimatedPrice  *money.Money `json:"estimated_price,omitempty"`   // Оценка админа за старую машину
+	Status          string       `json:"status"`                      // pending, evaluated, accepted, rejected
+	UserPayment     *money.Money `json:"user_payment,omitempty"`      // Сколько юзер готов доплатить
+	KolesaSearchURL string       `json:"kolesa_search_url,omitempty"` // Ссылка на kolesa.kz
+	CreatedAt       time.Time    `json:"created_at"`
+	UpdatedAt       time.Time    `json:"updated_at"`
 }
 
 type CreateTradeInRequest struct {
@@ -27,11 +31,11 @@ type CreateTradeInRequest struct {
 }
 
 type EvaluateTradeInRequest struct {
-	EstimatedPrice float64 `json:"estimated_price" binding:"required,min=0"`
+	EstimatedPrice money.Money `json:"estimated_price" binding:"required,min=0"`
 }
 
 type SetUserPaymentRequest struct {
-	UserPayment float64 `json:"user_payment" binding:"required,min=0"` // Сколько готов доплатить
+	UserPayment money.Money `json:"user_payment" binding:"required,min=0"` // Сколько готов доплатить
 }
 
 type TradeInResponse struct {
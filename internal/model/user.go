@@ -3,9 +3,11 @@ package model
 import "time"
 
 type User struct {
-	ID           int64     `json:"id"`
-	Email        string    `json:"email"`
-	PasswordHash string    `json:"-"`
-	Role         string    `json:"role"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           int64  `json:"id"`
+	Email        string `json:"email"`
+	PasswordHash string `json:"-"`
+	Role         string `json:"role"`
+	// валюта, в которой показывать цены; пусто — как хранится
+	PreferredCurrency string    `json:"preferred_currency,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
 }
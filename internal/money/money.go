@@ -0,0 +1,280 @@
+// Package money — денежные суммы с фиксированной точкой.
+//
+// Money хранит сумму в минимальных единицах (центах, тиынах, копейках),
+// поэтому сложение и сравнение точные, а значения NUMERIC из базы
+// и числа из JSON проходят туда и обратно без потерь.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Scale — знаков после запятой, MinorPerUnit — минимальных единиц в одной целой
+const (
+	Scale        = 2
+	MinorPerUnit = 100
+)
+
+var ErrInvalidAmount = errors.New("invalid money amount")
+
+// Money — сумма в минимальных единицах. Сравнивается обычными < и ==.
+type Money int64
+
+// FromMinor — сумма из минимальных единиц: FromMinor(12345) == 123.45
+func FromMinor(minor int64) Money {
+	return Money(minor)
+}
+
+// FromUnits — сумма из целых единиц: FromUnits(450) == 450.00
+func FromUnits(units int64) Money {
+	return Money(units * MinorPerUnit)
+}
+
+// FromFloat округляет float до минимальной единицы. Только для значений,
+// которые изначально не были деньгами (статистика, пересчёт по курсу).
+func FromFloat(f float64) Money {
+	return Money(math.Round(f * MinorPerUnit))
+}
+
+// Parse разбирает десятичную запись "1234.5", "-10", "0.05".
+// Больше двух знаков после запятой — ошибка, если это не нули.
+func Parse(s string) (Money, error) {
+	return parse(s, false)
+}
+
+// parse: round == true — лишние знаки округляются (половина — от нуля);
+// нужно для NUMERIC без масштаба и агрегатов вроде AVG.
+func parse(s string, round bool) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("%w: empty", ErrInvalidAmount)
+	}
+
+	neg := false
+	switch s[0] {
+	case '-':
+		neg = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+	if !allDigits(intPart) || !allDigits(fracPart) {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	roundUp := false
+	if len(fracPart) > Scale {
+		extra := fracPart[Scale:]
+		fracPart = fracPart[:Scale]
+		switch {
+		case strings.Trim(extra, "0") == "":
+		case round:
+			roundUp = extra[0] >= '5'
+		default:
+			return 0, fmt.Errorf("%w: more than %d decimal places", ErrInvalidAmount, Scale)
+		}
+	}
+	fracPart += strings.Repeat("0", Scale-len(fracPart))
+
+	digits := strings.TrimLeft(intPart+fracPart, "0")
+	if digits == "" {
+		digits = "0"
+	}
+	v, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+	}
+	if roundUp {
+		if v == math.MaxInt64 {
+			return 0, fmt.Errorf("%w: out of range", ErrInvalidAmount)
+		}
+		v++
+	}
+	if neg {
+		v = -v
+	}
+	return Money(v), nil
+}
+
+func allDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Minor — сумма в минимальных единицах
+func (m Money) Minor() int64 {
+	return int64(m)
+}
+
+// Units — целая часть суммы, копейки отбрасываются
+func (m Money) Units() int64 {
+	return int64(m) / MinorPerUnit
+}
+
+// Float64 — для статистики и весов; для расчётов не использовать
+func (m Money) Float64() float64 {
+	return float64(m) / MinorPerUnit
+}
+
+// Mul — сумма, умноженная на целое
+func (m Money) Mul(n int64) Money {
+	return m * Money(n)
+}
+
+// MulFrac умножает на дробь num/den с округлением половины от нуля:
+// MulFrac(85, 100) — 85% суммы. Если m*num не помещается в int64,
+// считается через big.Int; результат за пределами int64 ограничивается
+// MaxInt64/MinInt64.
+func (m Money) MulFrac(num, den int64) Money {
+	if m == math.MinInt64 || num == math.MinInt64 || den == math.MinInt64 ||
+		(num != 0 && abs(int64(m)) > math.MaxInt64/abs(num)) {
+		return m.mulFracBig(num, den)
+	}
+
+	p := int64(m) * num
+	q, r := p/den, p%den
+	if r < 0 {
+		r = -r
+	}
+	// 2*r >= |den| без переполнения
+	if r >= abs(den)-r {
+		if (p < 0) != (den < 0) {
+			q--
+		} else {
+			q++
+		}
+	}
+	return Money(q)
+}
+
+func (m Money) mulFracBig(num, den int64) Money {
+	p := new(big.Int).Mul(big.NewInt(int64(m)), big.NewInt(num))
+	d := big.NewInt(den)
+	q, r := new(big.Int).QuoRem(p, d, new(big.Int))
+
+	r.Abs(r).Lsh(r, 1)
+	if r.Cmp(new(big.Int).Abs(d)) >= 0 {
+		if p.Sign()*d.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+
+	switch {
+	case q.IsInt64():
+		return Money(q.Int64())
+	case q.Sign() > 0:
+		return math.MaxInt64
+	default:
+		return math.MinInt64
+	}
+}
+
+// MulRate пересчитывает сумму по курсу с округлением до минимальной единицы
+func (m Money) MulRate(rate float64) Money {
+	return Money(math.Round(float64(m) * rate))
+}
+
+func abs(n int64) int64 {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// String — всегда два знака после запятой: "1234.50"
+func (m Money) String() string {
+	v := int64(m)
+	sign := ""
+	if v < 0 {
+		sign = "-"
+	}
+	u := uint64(v)
+	if v < 0 {
+		u = uint64(-v)
+	}
+	return fmt.Sprintf("%s%d.%0*d", sign, u/MinorPerUnit, Scale, u%MinorPerUnit)
+}
+
+// MarshalJSON пишет сумму числом: 1234.50
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON принимает и число (1234.5), и строку ("1234.50")
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if unq, err := strconv.Unquote(s); err == nil {
+		s = unq
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value — для database/sql: NUMERIC передаётся строкой без потерь
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan — для database/sql. NULL в Money не сканируется,
+// для nullable колонок используйте *Money.
+func (m *Money) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return m.scanString(string(v))
+	case string:
+		return m.scanString(v)
+	case int64:
+		*m = FromUnits(v)
+		return nil
+	case float64:
+		*m = FromFloat(v)
+		return nil
+	case nil:
+		return fmt.Errorf("%w: NULL", ErrInvalidAmount)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+}
+
+func (m *Money) scanString(s string) error {
+	v, err := parse(s, true)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Set — для flag.Var
+func (m *Money) Set(s string) error {
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
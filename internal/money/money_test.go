@@ -0,0 +1,202 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{"0", 0},
+		{"1", 100},
+		{"1234.5", 123450},
+		{"1234.50", 123450},
+		{"0.05", 5},
+		{".5", 50},
+		{"5.", 500},
+		{"-10", -1000},
+		{"+10.01", 1001},
+		{"  7.25 ", 725},
+		{"007.10", 710},
+		{"1.2300", 123}, // лишние нули допустимы
+		{"92233720368547758.07", math.MaxInt64},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, in := range []string{
+		"", " ", "-", ".", "abc", "1,5", "1.2.3", "1e3", "--1", "1.005",
+		"92233720368547758.08",
+	} {
+		if _, err := Parse(in); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Parse(%q) err = %v, want ErrInvalidAmount", in, err)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{123450, "1234.50"},
+		{-5, "-0.05"},
+		{-123456, "-1234.56"},
+		{math.MinInt64, "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(tt.in), got, tt.want)
+		}
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	type payload struct {
+		Price Money  `json:"price"`
+		Bid   *Money `json:"bid"`
+	}
+
+	in := payload{Price: FromMinor(1234567)}
+	data, err := json.Marshal(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"price":12345.67,"bid":null}` {
+		t.Errorf("marshal = %s", data)
+	}
+
+	var out payload
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	if out.Price != in.Price || out.Bid != nil {
+		t.Errorf("round trip = %+v, want %+v", out, in)
+	}
+
+	// и число, и строка
+	for _, src := range []string{`{"price":10.5}`, `{"price":"10.50"}`, `{"price":"10.5"}`} {
+		var p payload
+		if err := json.Unmarshal([]byte(src), &p); err != nil {
+			t.Errorf("unmarshal %s: %v", src, err)
+			continue
+		}
+		if p.Price != 1050 {
+			t.Errorf("unmarshal %s = %d, want 1050", src, p.Price)
+		}
+	}
+
+	for _, src := range []string{`{"price":10.555}`, `{"price":"x"}`, `{"price":true}`} {
+		var p payload
+		if err := json.Unmarshal([]byte(src), &p); err == nil {
+			t.Errorf("unmarshal %s: expected error", src)
+		}
+	}
+}
+
+func TestSQLRoundTrip(t *testing.T) {
+	for _, m := range []Money{0, 1, -1, 123450, -99999999, math.MaxInt64} {
+		v, err := m.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var back Money
+		if err := back.Scan(v); err != nil {
+			t.Errorf("Scan(%v) error: %v", v, err)
+			continue
+		}
+		if back != m {
+			t.Errorf("round trip %d -> %v -> %d", m, v, back)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src  interface{}
+		want Money
+	}{
+		{[]byte("1234.50"), 123450},
+		{"1234.5", 123450},
+		{int64(12), 1200},
+		{float64(12.345), 1235},
+		// NUMERIC без масштаба и AVG — округление половины от нуля
+		{"1.005", 101},
+		{"1.0049", 100},
+		{"-1.005", -101},
+		{[]byte("0.125000"), 13},
+	}
+	for _, tt := range tests {
+		var m Money
+		if err := m.Scan(tt.src); err != nil {
+			t.Errorf("Scan(%v) error: %v", tt.src, err)
+			continue
+		}
+		if m != tt.want {
+			t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+		}
+	}
+
+	for _, src := range []interface{}{nil, true, "abc", "92233720368547758.075"} {
+		var m Money
+		if err := m.Scan(src); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Scan(%v) err = %v, want ErrInvalidAmount", src, err)
+		}
+	}
+}
+
+func TestMulFrac(t *testing.T) {
+	tests := []struct {
+		name     string
+		m        Money
+		num, den int64
+		want     Money
+	}{
+		{"85%", 10000, 85, 100, 8500},
+		{"115%", 10000, 115, 100, 11500},
+		{"half rounds up", 1, 1, 2, 1},
+		{"below half rounds down", 1, 1, 3, 0},
+		{"above half rounds up", 2, 1, 3, 1},
+		{"negative half rounds away from zero", -1, 1, 2, -1},
+		{"negative below half", -1, 1, 3, 0},
+		{"negative denominator", 1, 1, -2, -1},
+		{"both negative", -1, 1, -2, 1},
+		{"exact", 999, 1, 3, 333},
+		{"per year", 1000000, 1, 7, 142857},
+
+		// m*num не помещается в int64, результат помещается
+		{"overflow, fraction below 1", math.MaxInt64, 85, 100, 7839866231326559436},
+		{"overflow, same fraction", math.MaxInt64 / 2, 3, 3, math.MaxInt64 / 2},
+		{"overflow, negative", math.MinInt64, 1, 2, math.MinInt64 / 2},
+		{"overflow, min int", math.MinInt64, 1, 1, math.MinInt64},
+
+		// результат не помещается — ограничивается
+		{"saturates high", math.MaxInt64, 115, 100, math.MaxInt64},
+		{"saturates low", math.MinInt64, 115, 100, math.MinInt64},
+		{"saturates negated min", math.MinInt64, -1, 1, math.MaxInt64},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.m.MulFrac(tt.num, tt.den); got != tt.want {
+				t.Errorf("%d.MulFrac(%d, %d) = %d, want %d", tt.m, tt.num, tt.den, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"database/sql"
+
+	"car-store/internal/model"
+)
+
+const auctionOfferColumns = `id, auction_id, user_id, amount, status, decided_by, decided_at, created_at`
+
+type AuctionOfferRepository struct {
+	db *sql.DB
+}
+
+func NewAuctionOfferRepository(db *sql.DB) *AuctionOfferRepository {
+	return &AuctionOfferRepository{db: db}
+}
+
+func scanAuctionOffer(row rowScanner, o *model.AuctionOffer) error {
+	return row.Scan(
+		&o.ID,
+		&o.AuctionID,
+		&o.UserID,
+		&o.Amount,
+		&o.Status,
+		&o.DecidedBy,
+		&o.DecidedAt,
+		&o.CreatedAt,
+	)
+}
+
+func scanAuctionOffers(rows *sql.Rows) ([]model.AuctionOffer, error) {
+	var list []model.AuctionOffer
+	for rows.Next() {
+		var o model.AuctionOffer
+		if err := scanAuctionOffer(rows, &o); err != nil {
+			return nil, err
+		}
+		list = append(list, o)
+	}
+	return list, rows.Err()
+}
+
+// Create возвращает false, если по аукциону уже есть нерассмотренное предложение
+func (r *AuctionOfferRepository) Create(o *model.AuctionOffer) (bool, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO auction_offers (auction_id, user_id, amount, status)
+		VALUES ($1, $2, $3, 'pending')
+		ON CONFLICT DO NOTHING
+		RETURNING id, status, created_at
+	`, o.AuctionID, o.UserID, o.Amount).Scan(&o.ID, &o.Status, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *AuctionOfferRepository) GetByID(id int64) (*model.AuctionOffer, error) {
+	var o model.AuctionOffer
+	err := scanAuctionOffer(r.db.QueryRow(`
+		SELECT `+auctionOfferColumns+`
+		FROM auction_offers
+		WHERE id = $1
+	`, id), &o)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *AuctionOfferRepository) GetByUser(userID int64) ([]model.AuctionOffer, error) {
+	rows, err := r.db.Query(`
+		SELECT `+auctionOfferColumns+`
+		FROM auction_offers
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuctionOffers(rows)
+}
+
+// GetAll — все предложения или только с данным статусом
+func (r *AuctionOfferRepository) GetAll(status string) ([]model.AuctionOffer, error) {
+	rows, err := r.db.Query(`
+		SELECT `+auctionOfferColumns+`
+		FROM auction_offers
+		WHERE $1 = '' OR status = $1
+		ORDER BY created_at
+	`, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAuctionOffers(rows)
+}
+
+// SetStatus меняет статус только из from.
+// Возвращает false, если предложение уже рассмотрено.
+func (r *AuctionOfferRepository) SetStatus(id int64, from, to string, decidedBy *int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE auction_offers
+		SET status = $1,
+		    decided_by = $2,
+		    decided_at = CASE WHEN $2::bigint IS NULL THEN NULL ELSE NOW() END
+		WHERE id = $3 AND status = $4
+	`, to, decidedBy, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
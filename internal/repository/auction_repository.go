@@ -2,10 +2,60 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"car-store/internal/model"
 )
 
+// auctionSelect — аукцион вместе с текущей ценой и числом ставок;
+// запрос дополняется условием WHERE и GROUP BY a.id.
+// reserve_met — NULL, если резервной цены нет.
+const auctionSelect = `
+	SELECT
+		a.id,
+		a.car_id,
+		a.start_price,
+		a.reserve_price,
+		a.buy_now_price,
+		a.start_time,
+		a.end_time,
+		a.scheduled_end_time,
+		a.status,
+		a.settled_at,
+		a.created_at,
+		a.version,
+		COALESCE(MAX(b.amount), a.start_price) AS current_price,
+		COUNT(b.id) AS bid_count,
+		CASE WHEN a.reserve_price IS NULL THEN NULL
+		     ELSE COALESCE(MAX(b.amount) >= a.reserve_price, FALSE)
+		END AS reserve_met
+	FROM auctions a
+	LEFT JOIN bids b ON b.auction_id = a.id
+`
+
+func scanAuction(row rowScanner, a *model.Auction) error {
+	return row.Scan(
+		&a.ID,
+		&a.CarID,
+		&a.StartPrice,
+		&a.ReservePrice,
+		&a.BuyNowPrice,
+		&a.StartTime,
+		&a.EndTime,
+		&a.ScheduledEndTime,
+		&a.Status,
+		&a.SettledAt,
+		&a.CreatedAt,
+		&a.Version,
+		&a.CurrentPrice,
+		&a.BidCount,
+		&a.ReserveMet,
+	)
+}
+
+// Времена аукциона лежат в TIMESTAMP без часового пояса и означают UTC:
+// всё, что пишется в них или сравнивается с ними, передаётся через .UTC(),
+// иначе на сервере не в UTC сроки сдвинутся на его смещение.
 type AuctionRepository struct {
 	db *sql.DB
 }
@@ -16,34 +66,28 @@ func NewAuctionRepository(db *sql.DB) *AuctionRepository {
 
 func (r *AuctionRepository) Create(a *model.Auction) error {
 	query := `
-		INSERT INTO auctions (car_id, start_price, start_time, end_time)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at
+		INSERT INTO auctions (car_id, start_price, reserve_price, buy_now_price, start_time, end_time, scheduled_end_time, status)
+		VALUES ($1, $2, $3, $6, $4, $5, $5, $7)
+		RETURNING id, scheduled_end_time, version, created_at
 	`
 
 	return r.db.QueryRow(
 		query,
 		a.CarID,
 		a.StartPrice,
-		a.StartTime,
-		a.EndTime,
-	).Scan(&a.ID, &a.CreatedAt)
+		a.ReservePrice,
+		a.StartTime.UTC(),
+		a.EndTime.UTC(),
+		a.BuyNowPrice,
+		a.Status,
+	).Scan(&a.ID, &a.ScheduledEndTime, &a.Version, &a.CreatedAt)
 }
 
 func (r *AuctionRepository) GetAll() ([]model.Auction, error) {
-	rows, err := r.db.Query(`
-		SELECT 
-			a.id, 
-			a.car_id, 
-			a.start_price, 
-			a.start_time, 
-			a.end_time, 
-			a.created_at,
-			COALESCE(MAX(b.amount), a.start_price) as current_price,
-			COUNT(b.id) as bid_count
-		FROM auctions a
-		LEFT JOIN bids b ON b.auction_id = a.id
-		GROUP BY a.id, a.car_id, a.start_price, a.start_time, a.end_time, a.created_at
+	// отменённые аукционы в списке не показываются
+	rows, err := r.db.Query(auctionSelect + `
+		WHERE a.status <> 'cancelled'
+		GROUP BY a.id
 	`)
 	if err != nil {
 		return nil, err
@@ -53,16 +97,7 @@ func (r *AuctionRepository) GetAll() ([]model.Auction, error) {
 	var auctions []model.Auction
 	for rows.Next() {
 		var a model.Auction
-		if err := rows.Scan(
-			&a.ID,
-			&a.CarID,
-			&a.StartPrice,
-			&a.StartTime,
-			&a.EndTime,
-			&a.CreatedAt,
-			&a.CurrentPrice,
-			&a.BidCount,
-		); err != nil {
+		if err := scanAuction(rows, &a); err != nil {
 			return nil, err
 		}
 		auctions = append(auctions, a)
@@ -70,52 +105,179 @@ func (r *AuctionRepository) GetAll() ([]model.Auction, error) {
 	return auctions, nil
 }
 
-func (r *AuctionRepository) Update(a *model.Auction) error {
+// Update — с проверкой версии, как CarRepository.Update.
+// Новый end_time становится и концом по расписанию: продление отсчитывается от него.
+func (r *AuctionRepository) Update(a *model.Auction) (bool, error) {
 	query := `
 		UPDATE auctions
-		SET car_id = $1,
-		    start_price = $2,
-		    start_time = $3,
-		    end_time = $4
-		WHERE id = $5
+		SET start_price = $1,
+		    start_time = $2,
+		    scheduled_end_time = CASE WHEN end_time = $3 THEN scheduled_end_time ELSE $3 END,
+		    end_time = $3,
+		    reserve_price = $6,
+		    buy_now_price = $7,
+		    status = $8,
+		    version = version + 1
+		WHERE id = $4 AND version = $5
+		RETURNING scheduled_end_time, version
 	`
-	_, err := r.db.Exec(
+	err := r.db.QueryRow(
 		query,
-		a.CarID,
 		a.StartPrice,
-		a.StartTime,
-		a.EndTime,
+		a.StartTime.UTC(),
+		a.EndTime.UTC(),
 		a.ID,
-	)
-	return err
+		a.Version,
+		a.ReservePrice,
+		a.BuyNowPrice,
+		a.Status,
+	).Scan(&a.ScheduledEndTime, &a.Version)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	return err == nil, err
 }
 
-func (r *AuctionRepository) Delete(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM auctions WHERE id = $1`, id)
-	return err
+// Cancel отменяет ещё не закончившийся аукцион; строка со ставками остаётся
+// в истории. Отдельной блокировки нет: UPDATE ждёт транзакцию ставки или
+// захвата расчёта, держащую строку, и перепроверяет условие на её результате,
+// так что аукцион, который уже закончился или рассчитывается, не отменится.
+func (r *AuctionRepository) Cancel(id int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE auctions
+		SET status = 'cancelled', version = version + 1
+		WHERE id = $1 AND status IN ('scheduled', 'active')
+		  AND end_time > NOW() AT TIME ZONE 'UTC'
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// GetOpen — ещё не закончившиеся аукционы (scheduled и active)
+func (r *AuctionRepository) GetOpen() ([]model.Auction, error) {
+	rows, err := r.db.Query(auctionSelect + `
+		WHERE a.status IN ('scheduled', 'active')
+		GROUP BY a.id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auctions []model.Auction
+	for rows.Next() {
+		var a model.Auction
+		if err := scanAuction(rows, &a); err != nil {
+			return nil, err
+		}
+		auctions = append(auctions, a)
+	}
+	return auctions, rows.Err()
+}
+
+// GetUpcoming — ещё не начавшиеся аукционы, ближайшие первыми
+func (r *AuctionRepository) GetUpcoming(now time.Time) ([]model.Auction, error) {
+	rows, err := r.db.Query(auctionSelect+`
+		WHERE a.status = 'scheduled' AND a.start_time > $1
+		GROUP BY a.id
+		ORDER BY a.start_time
+	`, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auctions []model.Auction
+	for rows.Next() {
+		var a model.Auction
+		if err := scanAuction(rows, &a); err != nil {
+			return nil, err
+		}
+		auctions = append(auctions, a)
+	}
+	return auctions, rows.Err()
+}
+
+// ActivateDue переводит начавшиеся аукционы из scheduled в active
+func (r *AuctionRepository) ActivateDue(now time.Time) (int64, error) {
+	res, err := r.db.Exec(`
+		UPDATE auctions
+		SET status = 'active'
+		WHERE status = 'scheduled' AND start_time <= $1 AND end_time > $1
+	`, now.UTC())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// GetDueForSettlement — закончившиеся, но ещё не рассчитанные аукционы,
+// включая те, чей захват старше staleBefore
+func (r *AuctionRepository) GetDueForSettlement(now, staleBefore time.Time) ([]model.Auction, error) {
+	rows, err := r.db.Query(auctionSelect+`
+		WHERE (a.status IN ('scheduled', 'active') AND a.end_time <= $1)
+		   OR (a.status = 'ended' AND a.settle_claimed_at <= $2)
+		GROUP BY a.id
+		ORDER BY a.end_time
+	`, now.UTC(), staleBefore.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var auctions []model.Auction
+	for rows.Next() {
+		var a model.Auction
+		if err := scanAuction(rows, &a); err != nil {
+			return nil, err
+		}
+		auctions = append(auctions, a)
+	}
+	return auctions, rows.Err()
+}
+
+// ClaimSettlement переводит закончившийся аукцион в ended и закрепляет расчёт
+// за вызывающим: из нескольких процессов условие выполнится ровно у одного.
+// Захват старше staleBefore (процесс упал посреди расчёта) можно перехватить.
+// false — аукцион продлён, отменён или уже рассчитывается.
+func (r *AuctionRepository) ClaimSettlement(id int64, now, staleBefore time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE auctions
+		SET status = 'ended', settle_claimed_at = $2, version = version + 1
+		WHERE id = $1
+		  AND ((status IN ('scheduled', 'active') AND end_time <= $2)
+		    OR (status = 'ended' AND settle_claimed_at <= $3))
+	`, id, now.UTC(), staleBefore.UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// FinishSettlement записывает итог расчёта: settled или no_sale
+func (r *AuctionRepository) FinishSettlement(id int64, status string, now time.Time) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE auctions
+		SET status = $2, settled_at = $3, version = version + 1
+		WHERE id = $1 AND status = 'ended'
+	`, id, status, now.UTC())
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
 }
 
 func (r *AuctionRepository) GetByID(id int64) (*model.Auction, error) {
-	query := `
-		SELECT 
-			a.id, 
-			a.car_id, 
-			a.start_price, 
-			a.start_time, 
-			a.end_time, 
-			a.created_at,
-			COALESCE(MAX(b.amount), a.start_price) as current_price,
-			COUNT(b.id) as bid_count
-		FROM auctions a
-		LEFT JOIN bids b ON b.auction_id = a.id
-		WHERE a.id = $1
-		GROUP BY a.id, a.car_id, a.start_price, a.start_time, a.end_time, a.created_at
-	`
 	var a model.Auction
-	err := r.db.QueryRow(query, id).Scan(
-		&a.ID, &a.CarID, &a.StartPrice, &a.StartTime, &a.EndTime, &a.CreatedAt,
-		&a.CurrentPrice, &a.BidCount,
-	)
+	err := scanAuction(r.db.QueryRow(auctionSelect+`
+		WHERE a.id = $1
+		GROUP BY a.id
+	`, id), &a)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -128,7 +290,8 @@ func (r *AuctionRepository) GetByID(id int64) (*model.Auction, error) {
 func (r *AuctionRepository) ExistsByCarID(carID int64) (bool, error) {
 	var exists bool
 	err := r.db.QueryRow(
-		"SELECT EXISTS (SELECT 1 FROM auctions WHERE car_id = $1)",
+		// после отмены или аукциона без продажи машину можно выставить снова
+		"SELECT EXISTS (SELECT 1 FROM auctions WHERE car_id = $1 AND status IN ('scheduled', 'active', 'ended'))",
 		carID,
 	).Scan(&exists)
 
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"database/sql"
+	"time"
 
 	"car-store/internal/model"
+	"car-store/internal/money"
 )
 
 type BidRepository struct {
@@ -14,26 +16,462 @@ func NewBidRepository(db *sql.DB) *BidRepository {
 	return &BidRepository{db: db}
 }
 
-func (r *BidRepository) Create(b *model.Bid) error {
-	query := `
-		INSERT INTO bids (auction_id, user_id, amount)
-		VALUES ($1, $2, $3)
+// Create в одной транзакции проверяет и записывает ставку, отвечает на неё
+// автоматическими ставками по ProxyBid и, если ставки пришли в окно soft close,
+// продлевает аукцион. Минимальная ставка и лимит ставок в минуту считаются
+// под блокировкой строки аукциона, поэтому параллельные ставки проверяются
+// по очереди: из двух одинаковых принимается первая, вторая получает BidTooLow.
+func (r *BidRepository) Create(b *model.Bid, rules model.BidRules) (model.BidResult, error) {
+	return r.inAuctionTx(b.AuctionID, rules.SoftClose, func(tx *sql.Tx, startPrice money.Money, res *model.BidResult) (bool, error) {
+		if rules.MaxBidsPerMinute > 0 {
+			count, err := recentManualBids(tx, b.UserID, b.AuctionID)
+			if err != nil {
+				return false, err
+			}
+			if count >= rules.MaxBidsPerMinute {
+				res.Status = model.BidRateLimited
+				return false, nil
+			}
+		}
+
+		l, err := currentLeader(tx, b.AuctionID)
+		if err != nil {
+			return false, err
+		}
+		if minBid := model.NextMinBid(rules.Increments, startPrice, l.price, l.bidCount); b.Amount < minBid {
+			res.Status, res.MinBid = model.BidTooLow, minBid
+			return false, nil
+		}
+
+		if err := insertBid(tx, b); err != nil {
+			return false, err
+		}
+		if _, err := resolveProxyBids(tx, b.AuctionID, startPrice, rules.Increments); err != nil {
+			return false, err
+		}
+		return true, nil
+	})
+}
+
+// SetProxyBid сохраняет максимум пользователя и сразу ставит за него,
+// если его максимум перебивает лидера. Максимум можно только поднять и
+// не ниже текущей минимальной ставки.
+func (r *BidRepository) SetProxyBid(p *model.ProxyBid, rules model.BidRules) (model.BidResult, error) {
+	return r.inAuctionTx(p.AuctionID, rules.SoftClose, func(tx *sql.Tx, startPrice money.Money, res *model.BidResult) (bool, error) {
+		var current money.Money
+		err := tx.QueryRow(`
+			SELECT max_amount
+			FROM auction_proxy_bids
+			WHERE auction_id = $1 AND user_id = $2
+		`, p.AuctionID, p.UserID).Scan(&current)
+		switch {
+		case err == nil:
+			if p.MaxAmount <= current {
+				res.Status, res.MinBid = model.BidProxyNotRaised, current
+				return false, nil
+			}
+		case err != sql.ErrNoRows:
+			return false, err
+		}
+
+		l, err := currentLeader(tx, p.AuctionID)
+		if err != nil {
+			return false, err
+		}
+		if minBid := model.NextMinBid(rules.Increments, startPrice, l.price, l.bidCount); p.MaxAmount < minBid {
+			res.Status, res.MinBid = model.BidTooLow, minBid
+			return false, nil
+		}
+
+		err = tx.QueryRow(`
+			INSERT INTO auction_proxy_bids (auction_id, user_id, max_amount)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (auction_id, user_id) DO UPDATE
+			SET max_amount = EXCLUDED.max_amount, updated_at = NOW()
+			RETURNING created_at, updated_at
+		`, p.AuctionID, p.UserID, p.MaxAmount).Scan(&p.CreatedAt, &p.UpdatedAt)
+		if err != nil {
+			return false, err
+		}
+		return resolveProxyBids(tx, p.AuctionID, startPrice, rules.Increments)
+	})
+}
+
+func (r *BidRepository) GetProxyBid(auctionID, userID int64) (*model.ProxyBid, error) {
+	var p model.ProxyBid
+	err := r.db.QueryRow(`
+		SELECT auction_id, user_id, max_amount, created_at, updated_at
+		FROM auction_proxy_bids
+		WHERE auction_id = $1 AND user_id = $2
+	`, auctionID, userID).Scan(&p.AuctionID, &p.UserID, &p.MaxAmount, &p.CreatedAt, &p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// DeleteProxyBid отменяет максимум; уже сделанные по нему ставки остаются
+func (r *BidRepository) DeleteProxyBid(auctionID, userID int64) (bool, error) {
+	res, err := r.db.Exec(`
+		DELETE FROM auction_proxy_bids
+		WHERE auction_id = $1 AND user_id = $2
+	`, auctionID, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// BuyNow под блокировкой аукциона записывает ставку покупателя по
+// buy_now_price и закрывает аукцион в этот же момент: ставки, ждавшие
+// блокировки, увидят закончившийся аукцион. false — аукцион ещё не начался,
+// закончился или отменён, buy_now_price не задана или ставки уже прошли порог cutoffPercent.
+func (r *BidRepository) BuyNow(b *model.Bid, cutoffPercent int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var buyNow *money.Money
+	var startTime, endTime time.Time
+	var status string
+	err = tx.QueryRow(`
+		SELECT buy_now_price, start_time, end_time, status
+		FROM auctions
+		WHERE id = $1
+		FOR UPDATE
+	`, b.AuctionID).Scan(&buyNow, &startTime, &endTime, &status)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	now := time.Now().UTC()
+	if !model.AuctionOpen(status) || now.Before(startTime) || !endTime.After(now) || buyNow == nil {
+		return false, nil
+	}
+
+	var price money.Money
+	var bidCount int
+	if err := tx.QueryRow(`
+		SELECT COALESCE(MAX(amount), 0), COUNT(*)
+		FROM bids
+		WHERE auction_id = $1
+	`, b.AuctionID).Scan(&price, &bidCount); err != nil {
+		return false, err
+	}
+	if !model.BuyNowOpen(buyNow, price, bidCount, cutoffPercent) {
+		return false, nil
+	}
+
+	b.Amount = *buyNow
+	b.IsAuto = false
+	if err := insertBid(tx, b); err != nil {
+		return false, err
+	}
+	if _, err := tx.Exec(`
+		UPDATE auctions SET end_time = $1, version = version + 1
+		WHERE id = $2
+	`, now, b.AuctionID); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// inAuctionTx блокирует строку аукциона и выполняет fn в той же транзакции,
+// поэтому ставки одного аукциона проверяются и пишутся строго по очереди,
+// а финализатор видит уже записанные ставки и продлённый end_time.
+// fn либо записывает отказ в res и возвращает false — тогда транзакция
+// откатывается, — либо сообщает, появились ли новые ставки: тогда аукцион
+// продлевается по правилу soft close.
+func (r *BidRepository) inAuctionTx(
+	auctionID int64,
+	rule model.SoftClose,
+	fn func(tx *sql.Tx, startPrice money.Money, res *model.BidResult) (bool, error),
+) (model.BidResult, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return model.BidResult{}, err
+	}
+	defer tx.Rollback()
+
+	var startPrice money.Money
+	var startTime, endTime, scheduledEnd time.Time
+	var status string
+	err = tx.QueryRow(`
+		SELECT start_price, start_time, end_time, scheduled_end_time, status
+		FROM auctions
+		WHERE id = $1
+		FOR UPDATE
+	`, auctionID).Scan(&startPrice, &startTime, &endTime, &scheduledEnd, &status)
+	if err == sql.ErrNoRows {
+		return model.BidResult{Status: model.BidAuctionClosed}, nil
+	}
+	if err != nil {
+		return model.BidResult{}, err
+	}
+
+	res := model.BidResult{Status: model.BidAccepted, EndTime: endTime}
+
+	now := time.Now().UTC()
+	if !model.AuctionOpen(status) || !endTime.After(now) {
+		res.Status = model.BidAuctionClosed
+		return res, nil
+	}
+	// статус scheduled может отставать от времени, решает start_time
+	if now.Before(startTime) {
+		res.Status = model.BidNotStarted
+		return res, nil
+	}
+
+	placed, err := fn(tx, startPrice, &res)
+	if err != nil {
+		return model.BidResult{}, err
+	}
+	if res.Status != model.BidAccepted {
+		return res, nil
+	}
+
+	if newEnd, ok := softCloseEnd(rule, now, endTime, scheduledEnd); placed && ok {
+		// версия растёт, чтобы PUT/PATCH со старым ETag не затёр продление
+		if _, err := tx.Exec(`
+			UPDATE auctions SET end_time = $1, version = version + 1
+			WHERE id = $2
+		`, newEnd, auctionID); err != nil {
+			return model.BidResult{}, err
+		}
+		res.EndTime = newEnd
+	}
+
+	if err := tx.Commit(); err != nil {
+		return model.BidResult{}, err
+	}
+	return res, nil
+}
+
+// recentManualBids — ручные ставки пользователя на аукцион за последнюю минуту
+func recentManualBids(tx *sql.Tx, userID, auctionID int64) (int, error) {
+	var count int
+	err := tx.QueryRow(`
+		SELECT COUNT(*)
+		FROM bids
+		WHERE user_id = $1
+		  AND auction_id = $2
+		  AND NOT is_auto
+		  AND created_at >= NOW() - INTERVAL '1 minute'
+	`, userID, auctionID).Scan(&count)
+	return count, err
+}
+
+func insertBid(tx *sql.Tx, b *model.Bid) error {
+	return tx.QueryRow(`
+		INSERT INTO bids (auction_id, user_id, amount, is_auto)
+		VALUES ($1, $2, $3, $4)
 		RETURNING id, created_at
-	`
-	return r.db.QueryRow(
-		query,
+	`,
 		b.AuctionID,
 		b.UserID,
 		b.Amount,
+		b.IsAuto,
 	).Scan(&b.ID, &b.CreatedAt)
 }
 
+// bidLeader — текущая лидирующая ставка
+type bidLeader struct {
+	userID   int64 // 0 — ставок нет
+	price    money.Money
+	bidCount int
+}
+
+// proxyMax — максимум ProxyBid одного пользователя
+type proxyMax struct {
+	userID int64
+	max    money.Money
+	setAt  time.Time
+}
+
+// autoBid — ставка, которую нужно записать за владельца максимума
+type autoBid struct {
+	userID int64
+	amount money.Money
+}
+
+// resolveProxyBids ставит за владельцев ProxyBid всё, что насчитал proxyBidsFor.
+// Возвращает true, если была записана хотя бы одна ставка.
+func resolveProxyBids(tx *sql.Tx, auctionID int64, startPrice money.Money, increments []model.BidIncrement) (bool, error) {
+	l, err := currentLeader(tx, auctionID)
+	if err != nil {
+		return false, err
+	}
+	maxes, err := proxyMaxes(tx, auctionID)
+	if err != nil {
+		return false, err
+	}
+
+	bids := proxyBidsFor(l, maxes, startPrice, increments)
+	for _, a := range bids {
+		if err := insertBid(tx, &model.Bid{AuctionID: auctionID, UserID: a.userID, Amount: a.amount, IsAuto: true}); err != nil {
+			return false, err
+		}
+	}
+	return len(bids) > 0, nil
+}
+
+// proxyBidsFor — автоматические ставки в порядке записи, пока чей-то максимум
+// перебивает лидера. Как на eBay: больший максимум побеждает и платит шаг
+// сверх второго (но не больше своего максимума), при равных максимумах
+// побеждает заданный раньше, а при равных ставках лидирует более ранняя.
+// Проигравший максимум записывается ставкой целиком, чтобы история
+// показывала, до чего дошёл торг.
+// Цена с каждым кругом растёт, а лидер меняется, поэтому цикл конечен.
+func proxyBidsFor(l bidLeader, maxes []proxyMax, startPrice money.Money, increments []model.BidIncrement) []autoBid {
+	var bids []autoBid
+	place := func(userID int64, amount money.Money) {
+		bids = append(bids, autoBid{userID: userID, amount: amount})
+		l.bidCount++
+		// равная ставка лидера не меняет: раньше записанная идёт первой
+		if amount > l.price {
+			l.userID, l.price = userID, amount
+		}
+	}
+
+	for {
+		minBid := model.NextMinBid(increments, startPrice, l.price, l.bidCount)
+		c := strongestChallenger(maxes, l.userID, minBid)
+		if c == nil {
+			return bids
+		}
+
+		// до какой суммы лидер готов защищаться
+		ceiling, leaderSetAt := l.price, time.Time{}
+		for _, m := range maxes {
+			if m.userID == l.userID && m.max > l.price {
+				ceiling, leaderSetAt = m.max, m.setAt
+			}
+		}
+
+		switch {
+		case c.max > ceiling:
+			// лидер отдаёт весь свой максимум, претендент ставит шаг сверх него
+			if ceiling > l.price {
+				place(l.userID, ceiling)
+			}
+			amount := model.NextMinBid(increments, startPrice, ceiling, l.bidCount)
+			if amount > c.max {
+				amount = c.max
+			}
+			place(c.userID, amount)
+
+		case c.max == ceiling && c.setAt.Before(leaderSetAt):
+			// равные максимумы, претендент задал свой раньше
+			place(c.userID, c.max)
+
+		case c.max == ceiling:
+			// равные максимумы, лидер задал свой раньше: его ставка идёт первой
+			place(l.userID, ceiling)
+			place(c.userID, c.max)
+
+		default:
+			// лидер перебивает максимум претендента шагом, насколько хватает своего
+			leaderID := l.userID
+			place(c.userID, c.max)
+			amount := model.NextMinBid(increments, startPrice, c.max, l.bidCount)
+			if amount > ceiling {
+				amount = ceiling
+			}
+			place(leaderID, amount)
+		}
+	}
+}
+
+// strongestChallenger — самый сильный чужой максимум, способный перебить лидера
+func strongestChallenger(maxes []proxyMax, leaderID int64, minBid money.Money) *proxyMax {
+	var best *proxyMax
+	for i := range maxes {
+		m := &maxes[i]
+		if m.userID == leaderID || m.max < minBid {
+			continue
+		}
+		if best == nil || m.max > best.max ||
+			m.max == best.max && (m.setAt.Before(best.setAt) ||
+				m.setAt.Equal(best.setAt) && m.userID < best.userID) {
+			best = m
+		}
+	}
+	return best
+}
+
+// currentLeader — при равных суммах лидирует более ранняя ставка
+func currentLeader(tx *sql.Tx, auctionID int64) (bidLeader, error) {
+	var l bidLeader
+	err := tx.QueryRow(`
+		SELECT user_id, amount,
+		       (SELECT COUNT(*) FROM bids WHERE auction_id = $1)
+		FROM bids
+		WHERE auction_id = $1
+		ORDER BY amount DESC, id ASC
+		LIMIT 1
+	`, auctionID).Scan(&l.userID, &l.price, &l.bidCount)
+	if err == sql.ErrNoRows {
+		return bidLeader{}, nil
+	}
+	return l, err
+}
+
+func proxyMaxes(tx *sql.Tx, auctionID int64) ([]proxyMax, error) {
+	rows, err := tx.Query(`
+		SELECT user_id, max_amount, updated_at
+		FROM auction_proxy_bids
+		WHERE auction_id = $1
+	`, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var maxes []proxyMax
+	for rows.Next() {
+		var m proxyMax
+		if err := rows.Scan(&m.userID, &m.max, &m.setAt); err != nil {
+			return nil, err
+		}
+		maxes = append(maxes, m)
+	}
+	return maxes, rows.Err()
+}
+
+// softCloseEnd — новый конец аукциона после ставки в момент now, если её нужно продлить
+func softCloseEnd(rule model.SoftClose, now, endTime, scheduledEnd time.Time) (time.Time, bool) {
+	if rule.Window <= 0 || endTime.Sub(now) > rule.Window {
+		return endTime, false
+	}
+
+	newEnd := now.Add(rule.Extension)
+	if rule.MaxExtension > 0 {
+		if limit := scheduledEnd.Add(rule.MaxExtension); newEnd.After(limit) {
+			newEnd = limit
+		}
+	}
+	return newEnd, newEnd.After(endTime)
+}
+
 func (r *BidRepository) GetMaxBidByAuctionID(auctionID int64) (*model.Bid, error) {
 	query := `
-		SELECT id, auction_id, user_id, amount, created_at
+		SELECT id, auction_id, user_id, amount, is_auto, created_at
 		FROM bids
 		WHERE auction_id = $1
-		ORDER BY amount DESC
+		ORDER BY amount DESC, id ASC
 		LIMIT 1
 	`
 
@@ -43,6 +481,7 @@ func (r *BidRepository) GetMaxBidByAuctionID(auctionID int64) (*model.Bid, error
 		&b.AuctionID,
 		&b.UserID,
 		&b.Amount,
+		&b.IsAuto,
 		&b.CreatedAt,
 	)
 
@@ -52,15 +491,45 @@ func (r *BidRepository) GetMaxBidByAuctionID(auctionID int64) (*model.Bid, error
 	return &b, err
 }
 
-func (r *BidRepository) UserBidsLimitInMinute(userID, auctionID int64) (int, error) {
-	query := `
-		SELECT COUNT(*)
-		FROM bids
-		WHERE user_id = $1
-		  AND auction_id = $2
-		  AND created_at >= NOW() - INTERVAL '1 minute'
-	`
-	var count int
-	err := r.db.QueryRow(query, userID, auctionID).Scan(&count)
-	return count, err
+// GetHistory — ставки аукциона по порядку записи. Номер участника — порядок
+// его первой ставки: ставки не удаляются, поэтому номера не меняются.
+func (r *BidRepository) GetHistory(auctionID int64) ([]model.BidHistoryItem, error) {
+	rows, err := r.db.Query(`
+		SELECT b.id, b.user_id, u.email, b.amount, b.is_auto, b.created_at,
+		       DENSE_RANK() OVER (ORDER BY f.first_bid) AS bidder_no
+		FROM bids b
+		JOIN (
+			SELECT user_id, MIN(id) AS first_bid
+			FROM bids
+			WHERE auction_id = $1
+			GROUP BY user_id
+		) f ON f.user_id = b.user_id
+		LEFT JOIN users u ON u.id = b.user_id
+		WHERE b.auction_id = $1
+		ORDER BY b.id
+	`, auctionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []model.BidHistoryItem
+	for rows.Next() {
+		var it model.BidHistoryItem
+		var userID int64
+		if err := rows.Scan(
+			&it.ID,
+			&userID,
+			&it.UserEmail,
+			&it.Amount,
+			&it.IsAuto,
+			&it.CreatedAt,
+			&it.BidderNo,
+		); err != nil {
+			return nil, err
+		}
+		it.UserID = &userID
+		items = append(items, it)
+	}
+	return items, rows.Err()
 }
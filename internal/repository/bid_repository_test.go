@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+func TestSoftCloseEnd(t *testing.T) {
+	scheduled := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	rule := model.SoftClose{
+		Window:       2 * time.Minute,
+		Extension:    2 * time.Minute,
+		MaxExtension: 10 * time.Minute,
+	}
+
+	tests := []struct {
+		name     string
+		rule     model.SoftClose
+		now      time.Time
+		endTime  time.Time
+		want     time.Time
+		extended bool
+	}{
+		{
+			name:    "ставка раньше окна",
+			rule:    rule,
+			now:     scheduled.Add(-3 * time.Minute),
+			endTime: scheduled,
+			want:    scheduled,
+		},
+		{
+			name:     "ставка ровно на границе окна продлевает",
+			rule:     model.SoftClose{Window: 2 * time.Minute, Extension: 3 * time.Minute},
+			now:      scheduled.Add(-2 * time.Minute),
+			endTime:  scheduled,
+			want:     scheduled.Add(time.Minute),
+			extended: true,
+		},
+		{
+			name:    "ставка за миг до окна не продлевает",
+			rule:    model.SoftClose{Window: 2 * time.Minute, Extension: 3 * time.Minute},
+			now:     scheduled.Add(-2*time.Minute - time.Nanosecond),
+			endTime: scheduled,
+			want:    scheduled,
+		},
+		{
+			name:     "ставка внутри окна",
+			rule:     rule,
+			now:      scheduled.Add(-30 * time.Second),
+			endTime:  scheduled,
+			want:     scheduled.Add(90 * time.Second),
+			extended: true,
+		},
+		{
+			name:     "продление упирается в MaxExtension",
+			rule:     rule,
+			now:      scheduled.Add(9 * time.Minute),
+			endTime:  scheduled.Add(10 * time.Minute),
+			want:     scheduled.Add(10 * time.Minute),
+			extended: false,
+		},
+		{
+			name:     "продление обрезается до MaxExtension",
+			rule:     rule,
+			now:      scheduled.Add(8*time.Minute + 30*time.Second),
+			endTime:  scheduled.Add(9 * time.Minute),
+			want:     scheduled.Add(10 * time.Minute),
+			extended: true,
+		},
+		{
+			name:     "MaxExtension == 0 — без ограничения",
+			rule:     model.SoftClose{Window: 2 * time.Minute, Extension: 2 * time.Minute},
+			now:      scheduled.Add(59 * time.Minute),
+			endTime:  scheduled.Add(60 * time.Minute),
+			want:     scheduled.Add(61 * time.Minute),
+			extended: true,
+		},
+		{
+			name:    "Window == 0 — продление выключено",
+			rule:    model.SoftClose{Extension: 2 * time.Minute, MaxExtension: 10 * time.Minute},
+			now:     scheduled.Add(-time.Second),
+			endTime: scheduled,
+			want:    scheduled,
+		},
+	}
+
+	for _, tt := range tests {
+		got, ok := softCloseEnd(tt.rule, tt.now, tt.endTime, scheduled)
+		if ok != tt.extended || (ok && !got.Equal(tt.want)) {
+			t.Errorf("%s: softCloseEnd = %v, %v; want %v, %v", tt.name, got, ok, tt.want, tt.extended)
+		}
+	}
+}
+
+func TestProxyBidsFor(t *testing.T) {
+	t1 := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	t2 := t1.Add(time.Minute)
+	increments := []model.BidIncrement{{Step: 1000}}
+	const start money.Money = 100000
+
+	tests := []struct {
+		name   string
+		leader bidLeader
+		maxes  []proxyMax
+		want   []autoBid
+	}{
+		{
+			name:   "максимумов нет",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+		},
+		{
+			name:  "первая ставка по максимуму — стартовая цена",
+			maxes: []proxyMax{{userID: 2, max: 500000, setAt: t1}},
+			want:  []autoBid{{2, start}},
+		},
+		{
+			name:   "максимум перебивает ручную ставку на шаг",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes:  []proxyMax{{userID: 2, max: 150000, setAt: t1}},
+			want:   []autoBid{{2, 101000}},
+		},
+		{
+			name:   "максимум ниже минимальной ставки не участвует",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes:  []proxyMax{{userID: 2, max: 100500, setAt: t1}},
+		},
+		{
+			name:   "победитель платит второй максимум плюс шаг",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 130000, setAt: t1},
+				{userID: 2, max: 150000, setAt: t2},
+			},
+			want: []autoBid{{1, 130000}, {2, 131000}},
+		},
+		{
+			name:   "цена не выше максимума победителя",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 130000, setAt: t1},
+				{userID: 2, max: 130500, setAt: t2},
+			},
+			want: []autoBid{{1, 130000}, {2, 130500}},
+		},
+		{
+			name:   "лидер защищается своим максимумом",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 200000, setAt: t2},
+				{userID: 2, max: 120000, setAt: t1},
+			},
+			want: []autoBid{{2, 120000}, {1, 121000}},
+		},
+		{
+			name:   "равные максимумы, лидер задал раньше — его ставка первая",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 130000, setAt: t1},
+				{userID: 2, max: 130000, setAt: t2},
+			},
+			want: []autoBid{{1, 130000}, {2, 130000}},
+		},
+		{
+			name:   "равные максимумы, претендент задал раньше",
+			leader: bidLeader{userID: 1, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 130000, setAt: t2},
+				{userID: 2, max: 130000, setAt: t1},
+			},
+			want: []autoBid{{2, 130000}},
+		},
+		{
+			name:   "несколько кругов: сильнейший ходит первым",
+			leader: bidLeader{userID: 3, price: start, bidCount: 1},
+			maxes: []proxyMax{
+				{userID: 1, max: 120000, setAt: t1},
+				{userID: 2, max: 150000, setAt: t2},
+			},
+			want: []autoBid{{2, 101000}, {1, 120000}, {2, 121000}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := proxyBidsFor(tt.leader, tt.maxes, start, increments)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: proxyBidsFor = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestStrongestChallenger(t *testing.T) {
+	t1 := time.Date(2026, 5, 1, 10, 0, 0, 0, time.UTC)
+	maxes := []proxyMax{
+		{userID: 4, max: 150000, setAt: t1},
+		{userID: 1, max: 200000, setAt: t1},
+		{userID: 3, max: 180000, setAt: t1.Add(time.Second)},
+		{userID: 2, max: 180000, setAt: t1},
+	}
+
+	tests := []struct {
+		leaderID int64
+		minBid   money.Money
+		want     int64 // 0 — претендента нет
+	}{
+		{leaderID: 0, minBid: 0, want: 1},
+		{leaderID: 1, minBid: 0, want: 2}, // при равных максимумах — заданный раньше
+		{leaderID: 1, minBid: 190000, want: 0},
+		{leaderID: 2, minBid: 160000, want: 1},
+	}
+	for _, tt := range tests {
+		var got int64
+		if c := strongestChallenger(maxes, tt.leaderID, tt.minBid); c != nil {
+			got = c.userID
+		}
+		if got != tt.want {
+			t.Errorf("strongestChallenger(leader %d, min %d) = user %d, want %d", tt.leaderID, tt.minBid, got, tt.want)
+		}
+	}
+}
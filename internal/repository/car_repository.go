@@ -2,8 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"fmt"
+	"strings"
 
 	"car-store/internal/model"
+	"car-store/internal/money"
+
+	"github.com/lib/pq"
 )
 
 type CarRepository struct {
@@ -14,11 +19,49 @@ func NewCarRepository(db *sql.DB) *CarRepository {
 	return &CarRepository{db: db}
 }
 
+// общий список колонок машины — порядок совпадает со scanCar
+var carColumns = prefixedCarColumns("cars")
+
+// prefixedCarColumns — то же самое для запросов с алиасом таблицы (JOIN)
+func prefixedCarColumns(alias string) string {
+	return strings.ReplaceAll(`
+		t.id, t.brand, t.model, t.year, t.price, t.status, t.is_auction_only,
+		COALESCE(t.vin, ''), COALESCE(t.external_id, ''), COALESCE(t.country, ''),
+		COALESCE(t.body_type, ''), t.currency, t.showroom_id,
+		t.version, t.created_at, t.deleted_at
+	`, "t.", alias+".")
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCar(row rowScanner, c *model.Car) error {
+	return row.Scan(
+		&c.ID,
+		&c.Brand,
+		&c.Model,
+		&c.Year,
+		&c.Price,
+		&c.Status,
+		&c.IsAuctionOnly,
+		&c.VIN,
+		&c.ExternalID,
+		&c.Country,
+		&c.BodyType,
+		&c.Currency,
+		&c.ShowroomID,
+		&c.Version,
+		&c.CreatedAt,
+		&c.DeletedAt,
+	)
+}
+
 func (r *CarRepository) Create(car *model.Car) error {
 	query := `
-		INSERT INTO cars (brand, model, year, price, status, is_auction_only)
-		VALUES ($1, $2, $3, $4, $5, $6)
-		RETURNING id, created_at
+		INSERT INTO cars (brand, model, year, price, status, is_auction_only, vin, external_id, country, body_type, currency, showroom_id)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), NULLIF($10, ''), $11, $12)
+		RETURNING id, version, created_at
 	`
 
 	return r.db.QueryRow(
@@ -29,77 +72,179 @@ func (r *CarRepository) Create(car *model.Car) error {
 		car.Price,
 		car.Status,
 		car.IsAuctionOnly,
-	).Scan(&car.ID, &car.CreatedAt)
+		car.VIN,
+		car.ExternalID,
+		car.Country,
+		car.BodyType,
+		car.Currency,
+		car.ShowroomID,
+	).Scan(&car.ID, &car.Version, &car.CreatedAt)
 }
 
+// GetAll — каталог: машины в архиве не возвращаются
 func (r *CarRepository) GetAll() ([]model.Car, error) {
+	rows, err := r.db.Query(`SELECT ` + carColumns + ` FROM cars WHERE deleted_at IS NULL`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCars(rows)
+}
+
+func (r *CarRepository) GetArchived() ([]model.Car, error) {
 	rows, err := r.db.Query(`
-		SELECT id, brand, model, year, price, status, is_auction_only, created_at
-		FROM cars
+		SELECT ` + carColumns + ` FROM cars
+		WHERE deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC
 	`)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var cars []model.Car
-	for rows.Next() {
-		var c model.Car
-		rows.Scan(
-			&c.ID,
-			&c.Brand,
-			&c.Model,
-			&c.Year,
-			&c.Price,
-			&c.Status,
-			&c.IsAuctionOnly,
-			&c.CreatedAt,
-		)
-		cars = append(cars, c)
-	}
-	return cars, nil
+	return scanCars(rows)
 }
+
 func (r *CarRepository) GetByID(id int64) (*model.Car, error) {
 	var c model.Car
-	err := r.db.QueryRow(`
-		SELECT id, brand, model, year, price, status, is_auction_only, created_at
-		FROM cars WHERE id = $1
-	`, id).Scan(
-		&c.ID,
-		&c.Brand,
-		&c.Model,
-		&c.Year,
-		&c.Price,
-		&c.Status,
-		&c.IsAuctionOnly,
-		&c.CreatedAt,
-	)
+	err := scanCar(r.db.QueryRow(`SELECT `+carColumns+` FROM cars WHERE id = $1`, id), &c)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	return &c, err
 }
 
-func (r *CarRepository) Update(c *model.Car) error {
-	_, err := r.db.Exec(`
-		UPDATE cars
-		SET brand=$1, model=$2, year=$3, price=$4, status=$5, is_auction_only=$6
-		WHERE id=$7
+func scanCars(rows *sql.Rows) ([]model.Car, error) {
+	var cars []model.Car
+	for rows.Next() {
+		var c model.Car
+		if err := scanCar(rows, &c); err != nil {
+			return nil, err
+		}
+		cars = append(cars, c)
+	}
+	return cars, rows.Err()
+}
+
+// Update пишет поля машины, только если её версия не изменилась
+// с момента чтения (c.Version). При успехе c.Version увеличивается.
+// Если изменилась цена или её валюта — тем же запросом пишется строка в car_price_history.
+// Если toStatus не пуст и отличается от c.Status, в той же транзакции меняется
+// статус с записью в car_status_history (reason), и версия растёт ещё раз.
+// Возвращает false, если машину успели изменить или удалить.
+func (r *CarRepository) Update(c *model.Car, changedBy *int64, toStatus, reason string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var version int
+	err = tx.QueryRow(`
+		WITH upd AS (
+			UPDATE cars
+			SET brand=$1, model=$2, year=$3, price=$4, is_auction_only=$5,
+			    vin=NULLIF($6, ''), external_id=NULLIF($7, ''), country=NULLIF($11, ''),
+			    body_type=NULLIF($12, ''), currency=$13,
+			    version = cars.version + 1
+			FROM (SELECT price AS old_price, currency AS old_currency FROM cars WHERE id=$8) prev
+			WHERE cars.id=$8 AND cars.version=$9
+			RETURNING cars.id, cars.version, cars.price, cars.currency, prev.old_price, prev.old_currency
+		), hist AS (
+			INSERT INTO car_price_history (car_id, old_price, old_currency, new_price, new_currency, changed_by)
+			SELECT id, old_price, old_currency, price, currency, $10 FROM upd
+			WHERE old_price IS DISTINCT FROM price OR old_currency <> currency
+		)
+		SELECT version FROM upd
 	`,
 		c.Brand,
 		c.Model,
 		c.Year,
 		c.Price,
-		c.Status,
 		c.IsAuctionOnly,
+		c.VIN,
+		c.ExternalID,
 		c.ID,
-	)
-	return err
+		c.Version,
+		changedBy,
+		c.Country,
+		c.BodyType,
+		c.Currency,
+	).Scan(&version)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if toStatus != "" && toStatus != c.Status {
+		ok, err := transitionStatusTx(tx, c.ID, c.Status, toStatus, changedBy, reason)
+		if err != nil || !ok {
+			return false, err
+		}
+		version++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+	c.Version = version
+	if toStatus != "" {
+		c.Status = toStatus
+	}
+	return true, nil
 }
 
-func (r *CarRepository) Delete(id int64) error {
-	_, err := r.db.Exec(`DELETE FROM cars WHERE id=$1`, id)
-	return err
+// условия, при которых машину нельзя убрать в архив ($1 — id машины),
+// в порядке полей model.CarArchiveBlockers
+var archiveBlockerConditions = []string{
+	`EXISTS (SELECT 1 FROM orders WHERE car_id = $1)`,
+	`EXISTS (SELECT 1 FROM auctions WHERE car_id = $1 AND status IN ('scheduled', 'active', 'ended'))`,
+	`EXISTS (SELECT 1 FROM tradeins WHERE desired_car_id = $1 AND status = 'accepted')`,
+	`EXISTS (SELECT 1 FROM reservations WHERE car_id = $1 AND status = 'active')`,
+}
+
+// Archive помечает машину удалённой. Сама строка остаётся,
+// чтобы заказы, аукционы и trade-in продолжали на неё ссылаться.
+// Блокеры (см. ArchiveBlockers) проверяются тем же запросом.
+// Возвращает false, если машина уже в архиве или что-то мешает её убрать.
+func (r *CarRepository) Archive(id int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE cars SET deleted_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+		  AND NOT (`+strings.Join(archiveBlockerConditions, " OR ")+`)
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *CarRepository) Restore(id int64) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE cars SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ArchiveBlockers — что мешает убрать машину в архив
+func (r *CarRepository) ArchiveBlockers(id int64) (model.CarArchiveBlockers, error) {
+	var b model.CarArchiveBlockers
+	err := r.db.QueryRow(`SELECT `+strings.Join(archiveBlockerConditions, ", "), id).Scan(
+		&b.HasOrder,
+		&b.HasActiveAuction,
+		&b.HasAcceptedTradeIn,
+		&b.HasActiveReservation,
+	)
+	return b, err
 }
 
 func (r *CarRepository) ExistsByID(id int64) (bool, error) {
@@ -111,3 +256,490 @@ func (r *CarRepository) ExistsByID(id int64) (bool, error) {
 
 	return exists, err
 }
+
+// TransitionStatus меняет статус только если текущий статус равен from,
+// и пишет запись в историю в той же транзакции.
+// Возвращает false, если статус машины уже успели поменять.
+func (r *CarRepository) TransitionStatus(id int64, from, to string, changedBy *int64, reason string) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ok, err := transitionStatusTx(tx, id, from, to, changedBy, reason)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// transitionStatusTx — смена статуса с записью в историю внутри чужой транзакции
+func transitionStatusTx(tx *sql.Tx, id int64, from, to string, changedBy *int64, reason string) (bool, error) {
+	res, err := tx.Exec(`
+		UPDATE cars SET status = $1, version = version + 1
+		WHERE id = $2 AND status = $3
+	`, to, id, from)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`
+		INSERT INTO car_status_history (car_id, from_status, to_status, changed_by, reason)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, from, to, changedBy, reason); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// TransferShowroom переносит машину в другой салон, если она всё ещё
+// в салоне from (nil — не закреплена), и пишет перемещение в car_transfers.
+// Возвращает false, если машину успели перенести, изменить или архивировать.
+func (r *CarRepository) TransferShowroom(t *model.CarTransfer, expectedVersion int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE cars SET showroom_id = $1, version = version + 1
+		WHERE id = $2 AND showroom_id IS NOT DISTINCT FROM $3
+		  AND version = $4 AND deleted_at IS NULL
+	`, t.ToShowroomID, t.CarID, t.FromShowroomID, expectedVersion)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 0 {
+		return false, nil
+	}
+
+	if err := tx.QueryRow(`
+		INSERT INTO car_transfers (car_id, from_showroom_id, to_showroom_id, transferred_by, reason)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at
+	`, t.CarID, t.FromShowroomID, t.ToShowroomID, t.TransferredBy, t.Reason).Scan(&t.ID, &t.CreatedAt); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+func (r *CarRepository) GetTransfers(carID int64) ([]model.CarTransfer, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, from_showroom_id, to_showroom_id,
+		       COALESCE(transferred_by, 0), COALESCE(reason, ''), created_at
+		FROM car_transfers
+		WHERE car_id = $1
+		ORDER BY created_at, id
+	`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.CarTransfer
+	for rows.Next() {
+		var t model.CarTransfer
+		if err := rows.Scan(
+			&t.ID,
+			&t.CarID,
+			&t.FromShowroomID,
+			&t.ToShowroomID,
+			&t.TransferredBy,
+			&t.Reason,
+			&t.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, t)
+	}
+	return list, rows.Err()
+}
+
+func (r *CarRepository) GetStatusHistory(carID int64) ([]model.CarStatusChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, from_status, to_status, changed_by, reason, created_at
+		FROM car_status_history
+		WHERE car_id = $1
+		ORDER BY created_at, id
+	`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.CarStatusChange
+	for rows.Next() {
+		var h model.CarStatusChange
+		if err := rows.Scan(
+			&h.ID,
+			&h.CarID,
+			&h.FromStatus,
+			&h.ToStatus,
+			&h.ChangedBy,
+			&h.Reason,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// GetFiltered — выборка машин по фильтрам (используется экспортом)
+func (r *CarRepository) GetFiltered(f model.CarFilter) ([]model.Car, error) {
+	query := `SELECT ` + carColumns + ` FROM cars WHERE 1=1`
+	args := []interface{}{}
+
+	if !f.IncludeArchived {
+		query += " AND deleted_at IS NULL"
+	}
+
+	add := func(cond string, v interface{}) {
+		args = append(args, v)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+
+	if f.Status != "" {
+		add("status = $%d", f.Status)
+	}
+	if f.Brand != "" {
+		add("LOWER(brand) = LOWER($%d)", f.Brand)
+	}
+	if f.YearFrom > 0 {
+		add("year >= $%d", f.YearFrom)
+	}
+	if f.YearTo > 0 {
+		add("year <= $%d", f.YearTo)
+	}
+	if f.PriceMin > 0 {
+		add("price >= $%d", f.PriceMin)
+	}
+	if f.PriceMax > 0 {
+		add("price <= $%d", f.PriceMax)
+	}
+	if f.City != "" {
+		add("showroom_id IN (SELECT id FROM showrooms WHERE LOWER(city) = LOWER($%d))", f.City)
+	}
+
+	query += " ORDER BY id"
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCars(rows)
+}
+
+// GetPriceHistory — изменения цены машины, от старых к новым
+func (r *CarRepository) GetPriceHistory(carID int64) ([]model.CarPriceChange, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, old_price, old_currency, new_price, new_currency, changed_by, created_at
+		FROM car_price_history
+		WHERE car_id = $1
+		ORDER BY created_at, id
+	`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var history []model.CarPriceChange
+	for rows.Next() {
+		var h model.CarPriceChange
+		if err := rows.Scan(
+			&h.ID,
+			&h.CarID,
+			&h.OldPrice,
+			&h.OldCurrency,
+			&h.NewPrice,
+			&h.NewCurrency,
+			&h.ChangedBy,
+			&h.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, rows.Err()
+}
+
+// UpsertMany записывает машины одной транзакцией, сопоставляя их
+// с существующими по VIN или external_id.
+//
+// allOrNothing — первая же ошибка откатывает всё, остальные строки помечаются skipped.
+// Иначе каждая строка пишется в своём SAVEPOINT и ошибка откатывает только её.
+// dryRun — всё выполняется, но транзакция в конце откатывается.
+// Возвращает результаты по каждой машине и признак того, что транзакция закоммичена.
+func (r *CarRepository) UpsertMany(cars []model.Car, allOrNothing, dryRun bool) ([]model.CarUpsertResult, bool, error) {
+	results := make([]model.CarUpsertResult, len(cars))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	for i := range cars {
+		if !allOrNothing {
+			if _, err := tx.Exec(`SAVEPOINT import_row`); err != nil {
+				return nil, false, err
+			}
+		}
+
+		id, prev, action, err := upsertCar(tx, &cars[i])
+		if err != nil {
+			results[i] = model.CarUpsertResult{Action: "failed", Err: err}
+
+			if allOrNothing {
+				for j := i + 1; j < len(cars); j++ {
+					results[j] = model.CarUpsertResult{Action: "skipped"}
+				}
+				return results, false, nil
+			}
+
+			if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT import_row`); err != nil {
+				return nil, false, err
+			}
+			continue
+		}
+
+		results[i] = model.CarUpsertResult{CarID: id, Action: action}
+		if prev != nil {
+			results[i].OldPrice = prev.OldPrice
+			results[i].OldCurrency = prev.OldCurrency
+			results[i].PriceChanged = true
+		}
+
+		if !allOrNothing {
+			if _, err := tx.Exec(`RELEASE SAVEPOINT import_row`); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+
+	if dryRun {
+		return results, false, nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+	return results, true, nil
+}
+
+// upsertCar возвращает id машины, запись об изменении цены (если цена
+// или валюта изменились) и действие. Пустая валюта в c заменяется действующей.
+func upsertCar(tx *sql.Tx, c *model.Car) (int64, *model.CarPriceChange, string, error) {
+	var id int64
+	var oldPrice money.Money
+	var oldCurrency, status string
+	var archived bool
+	var err error = sql.ErrNoRows
+
+	if c.VIN != "" {
+		err = tx.QueryRow(`
+			SELECT id, price, currency, status, deleted_at IS NOT NULL
+			FROM cars WHERE vin = $1 FOR UPDATE
+		`, c.VIN).Scan(&id, &oldPrice, &oldCurrency, &status, &archived)
+	}
+	if err == sql.ErrNoRows && c.ExternalID != "" {
+		err = tx.QueryRow(`
+			SELECT id, price, currency, status, deleted_at IS NOT NULL
+			FROM cars WHERE external_id = $1 FOR UPDATE
+		`, c.ExternalID).Scan(&id, &oldPrice, &oldCurrency, &status, &archived)
+	}
+
+	switch {
+	case err == sql.ErrNoRows:
+		if c.Currency == "" {
+			c.Currency = model.DefaultCurrency
+		}
+		err = tx.QueryRow(`
+			INSERT INTO cars (brand, model, year, price, status, is_auction_only, vin, external_id, country, body_type, currency)
+			VALUES ($1, $2, $3, $4, 'available', $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), NULLIF($9, ''), $10)
+			RETURNING id
+		`,
+			c.Brand,
+			c.Model,
+			c.Year,
+			c.Price,
+			c.IsAuctionOnly,
+			c.VIN,
+			c.ExternalID,
+			c.Country,
+			c.BodyType,
+			c.Currency,
+		).Scan(&id)
+		return id, nil, "created", err
+
+	case err != nil:
+		return 0, nil, "", err
+
+	// архивную и проданную машину импорт не трогает — строка считается неудачной
+	case archived:
+		return id, nil, "", fmt.Errorf("car %d is archived", id)
+	case status == model.CarStatusSold:
+		return id, nil, "", fmt.Errorf("car %d is sold", id)
+	}
+
+	if c.Currency == "" {
+		c.Currency = oldCurrency
+	}
+
+	// статус не трогаем — он меняется только через переходы
+	_, err = tx.Exec(`
+		UPDATE cars
+		SET brand=$1, model=$2, year=$3, price=$4, is_auction_only=$5,
+		    vin=COALESCE(NULLIF($6, ''), vin),
+		    external_id=COALESCE(NULLIF($7, ''), external_id),
+		    country=COALESCE(NULLIF($9, ''), country),
+		    body_type=COALESCE(NULLIF($10, ''), body_type),
+		    currency=$11,
+		    version = version + 1
+		WHERE id=$8
+	`,
+		c.Brand,
+		c.Model,
+		c.Year,
+		c.Price,
+		c.IsAuctionOnly,
+		c.VIN,
+		c.ExternalID,
+		id,
+		c.Country,
+		c.BodyType,
+		c.Currency,
+	)
+	if err != nil {
+		return 0, nil, "", err
+	}
+
+	if oldPrice == c.Price && oldCurrency == c.Currency {
+		return id, nil, "updated", nil
+	}
+	change := &model.CarPriceChange{
+		CarID:       id,
+		OldPrice:    oldPrice,
+		OldCurrency: oldCurrency,
+		NewPrice:    c.Price,
+		NewCurrency: c.Currency,
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO car_price_history (car_id, old_price, old_currency, new_price, new_currency)
+		VALUES ($1, $2, $3, $4, $5)
+	`, id, oldPrice, oldCurrency, c.Price, c.Currency); err != nil {
+		return 0, nil, "", err
+	}
+	return id, change, "updated", nil
+}
+
+// GetByIDs — машины по списку id (без архивных), порядок не гарантируется
+func (r *CarRepository) GetByIDs(ids []int64) ([]model.Car, error) {
+	rows, err := r.db.Query(`
+		SELECT `+carColumns+` FROM cars
+		WHERE id = ANY($1) AND deleted_at IS NULL
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanCars(rows)
+}
+
+// GetCatalogAveragePrice — средняя цена машин в продаже, в тенге
+func (r *CarRepository) GetCatalogAveragePrice() (money.Money, error) {
+	var avg money.Money
+	err := r.db.QueryRow(`
+		SELECT COALESCE(AVG(c.price * er.kzt_per_unit), 0)
+		FROM cars c
+		JOIN exchange_rates er ON er.currency = c.currency
+		WHERE c.status = 'available' AND c.deleted_at IS NULL
+	`).Scan(&avg)
+	return avg, err
+}
+
+func (r *CarRepository) GetFavoriteCounts(ids []int64) (map[int64]int, error) {
+	rows, err := r.db.Query(`
+		SELECT car_id, COUNT(*)
+		FROM favorites
+		WHERE car_id = ANY($1)
+		GROUP BY car_id
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[int64]int{}
+	for rows.Next() {
+		var id int64
+		var n int
+		if err := rows.Scan(&id, &n); err != nil {
+			return nil, err
+		}
+		counts[id] = n
+	}
+	return counts, rows.Err()
+}
+
+// GetLatestAuctions — последний неотменённый аукцион каждой машины из списка
+func (r *CarRepository) GetLatestAuctions(ids []int64) (map[int64]model.CarAuctionSummary, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ON (a.car_id)
+			a.car_id,
+			a.id,
+			a.status,
+			a.start_time,
+			a.end_time,
+			COALESCE((SELECT MAX(b.amount) FROM bids b WHERE b.auction_id = a.id), a.start_price),
+			(SELECT COUNT(*) FROM bids b WHERE b.auction_id = a.id)
+		FROM auctions a
+		WHERE a.car_id = ANY($1) AND a.status <> 'cancelled'
+		ORDER BY a.car_id, a.created_at DESC
+	`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := map[int64]model.CarAuctionSummary{}
+	for rows.Next() {
+		var carID int64
+		var s model.CarAuctionSummary
+		if err := rows.Scan(
+			&carID,
+			&s.AuctionID,
+			&s.Status,
+			&s.StartTime,
+			&s.EndTime,
+			&s.CurrentPrice,
+			&s.BidCount,
+		); err != nil {
+			return nil, err
+		}
+		result[carID] = s
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"database/sql"
+
+	"car-store/internal/model"
+)
+
+type DocumentRepository struct {
+	db *sql.DB
+}
+
+func NewDocumentRepository(db *sql.DB) *DocumentRepository {
+	return &DocumentRepository{db: db}
+}
+
+const documentColumns = `id, car_id, doc_type, visibility, file_name, content_type, size_bytes, storage_key, uploaded_by, created_at`
+
+func scanDocument(row rowScanner, d *model.CarDocument) error {
+	return row.Scan(
+		&d.ID,
+		&d.CarID,
+		&d.Type,
+		&d.Visibility,
+		&d.FileName,
+		&d.ContentType,
+		&d.SizeBytes,
+		&d.StorageKey,
+		&d.UploadedBy,
+		&d.CreatedAt,
+	)
+}
+
+func (r *DocumentRepository) Create(d *model.CarDocument) error {
+	return r.db.QueryRow(`
+		INSERT INTO car_documents (car_id, doc_type, visibility, file_name, content_type, size_bytes, storage_key, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`,
+		d.CarID,
+		d.Type,
+		d.Visibility,
+		d.FileName,
+		d.ContentType,
+		d.SizeBytes,
+		d.StorageKey,
+		d.UploadedBy,
+	).Scan(&d.ID, &d.CreatedAt)
+}
+
+func (r *DocumentRepository) GetByID(id int64) (*model.CarDocument, error) {
+	var d model.CarDocument
+	err := scanDocument(r.db.QueryRow(`SELECT `+documentColumns+` FROM car_documents WHERE id = $1`, id), &d)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *DocumentRepository) GetByCar(carID int64) ([]model.CarDocument, error) {
+	rows, err := r.db.Query(`
+		SELECT `+documentColumns+` FROM car_documents
+		WHERE car_id = $1
+		ORDER BY created_at, id
+	`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.CarDocument
+	for rows.Next() {
+		var d model.CarDocument
+		if err := scanDocument(rows, &d); err != nil {
+			return nil, err
+		}
+		list = append(list, d)
+	}
+	return list, rows.Err()
+}
+
+func (r *DocumentRepository) Delete(id int64) (bool, error) {
+	res, err := r.db.Exec(`DELETE FROM car_documents WHERE id = $1`, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// IsBuyer — пользователь купил машину или держит на неё активную бронь
+func (r *DocumentRepository) IsBuyer(userID, carID int64) (bool, error) {
+	var ok bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM orders WHERE user_id = $1 AND car_id = $2)
+		    OR EXISTS (SELECT 1 FROM reservations WHERE user_id = $1 AND car_id = $2 AND status = 'active')
+	`, userID, carID).Scan(&ok)
+	return ok, err
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+
+	"car-store/internal/model"
+)
+
+type ExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) *ExchangeRateRepository {
+	return &ExchangeRateRepository{db: db}
+}
+
+func (r *ExchangeRateRepository) GetAll() ([]model.ExchangeRate, error) {
+	rows, err := r.db.Query(`
+		SELECT currency, kzt_per_unit, updated_by, updated_at
+		FROM exchange_rates
+		ORDER BY currency
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.ExchangeRate
+	for rows.Next() {
+		var er model.ExchangeRate
+		if err := rows.Scan(&er.Currency, &er.KZTPerUnit, &er.UpdatedBy, &er.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, er)
+	}
+	return list, rows.Err()
+}
+
+func (r *ExchangeRateRepository) Get(currency string) (*model.ExchangeRate, error) {
+	var er model.ExchangeRate
+	err := r.db.QueryRow(`
+		SELECT currency, kzt_per_unit, updated_by, updated_at
+		FROM exchange_rates
+		WHERE currency = $1
+	`, currency).Scan(&er.Currency, &er.KZTPerUnit, &er.UpdatedBy, &er.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &er, err
+}
+
+func (r *ExchangeRateRepository) Upsert(er *model.ExchangeRate) error {
+	return r.db.QueryRow(`
+		INSERT INTO exchange_rates (currency, kzt_per_unit, updated_by, updated_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (currency) DO UPDATE
+		SET kzt_per_unit = EXCLUDED.kzt_per_unit,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING updated_at
+	`, er.Currency, er.KZTPerUnit, er.UpdatedBy).Scan(&er.UpdatedAt)
+}
@@ -43,34 +43,36 @@ func (r *FavoriteRepository) Exists(userID, carID int64) (bool, error) {
 
 func (r *FavoriteRepository) GetByUser(userID int64) ([]model.Car, error) {
 	rows, err := r.db.Query(`
-		SELECT c.id, c.brand, c.model, c.year, c.price,
-		       c.status, c.is_auction_only, c.created_at
+		SELECT `+prefixedCarColumns("c")+`
 		FROM cars c
 		JOIN favorites f ON f.car_id = c.id
-		WHERE f.user_id = $1
+		WHERE f.user_id = $1 AND c.deleted_at IS NULL
 	`, userID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var cars []model.Car
+	return scanCars(rows)
+}
+
+// GetUserIDsByCar — кто добавил машину в избранное
+func (r *FavoriteRepository) GetUserIDsByCar(carID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT user_id FROM favorites WHERE car_id = $1
+	`, carID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
 	for rows.Next() {
-		var c model.Car
-		if err := rows.Scan(
-			&c.ID,
-			&c.Brand,
-			&c.Model,
-			&c.Year,
-			&c.Price,
-			&c.Status,
-			&c.IsAuctionOnly,
-			&c.CreatedAt,
-		); err != nil {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		cars = append(cars, c)
+		ids = append(ids, id)
 	}
-
-	return cars, nil
+	return ids, rows.Err()
 }
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"database/sql"
+
+	"car-store/internal/model"
+)
+
+type NotificationRepository struct {
+	db *sql.DB
+}
+
+func NewNotificationRepository(db *sql.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(n *model.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, kind, message)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(query, n.UserID, n.Kind, n.Message).Scan(&n.ID, &n.CreatedAt)
+}
+
+func (r *NotificationRepository) GetByUser(userID int64) ([]model.Notification, error) {
+	rows, err := r.db.Query(`
+		SELECT id, user_id, kind, message, is_read, created_at
+		FROM notifications
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Notification
+	for rows.Next() {
+		var n model.Notification
+		if err := rows.Scan(
+			&n.ID,
+			&n.UserID,
+			&n.Kind,
+			&n.Message,
+			&n.IsRead,
+			&n.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, n)
+	}
+	return list, rows.Err()
+}
+
+func (r *NotificationRepository) MarkRead(userID, id int64) error {
+	_, err := r.db.Exec(`
+		UPDATE notifications SET is_read = TRUE
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	return err
+}
@@ -14,24 +14,56 @@ func NewOrderRepository(db *sql.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-func (r *OrderRepository) Create(o *model.Order) error {
-	query := `
-		INSERT INTO orders (user_id, car_id, total_price, source)
-		VALUES ($1, $2, $3, $4)
+// CreateSellingCar в одной транзакции переводит машину из fromStatus в sold
+// и создаёт заказ: проданной машины без заказа не бывает.
+// reservationID != nil — бронь покупателя закрывается как completed там же.
+// Возвращает false, если статус машины или брони уже успели поменять.
+func (r *OrderRepository) CreateSellingCar(o *model.Order, fromStatus, reason string, reservationID *int64) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	ok, err := transitionStatusTx(tx, o.CarID, fromStatus, model.CarStatusSold, nil, reason)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if reservationID != nil {
+		res, err := tx.Exec(`
+			UPDATE reservations SET status = 'completed'
+			WHERE id = $1 AND user_id = $2 AND status = 'active'
+		`, *reservationID, o.UserID)
+		if err != nil {
+			return false, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil || n == 0 {
+			return false, err
+		}
+	}
+
+	if err := tx.QueryRow(`
+		INSERT INTO orders (user_id, car_id, total_price, currency, source)
+		VALUES ($1, $2, $3, $4, $5)
 		RETURNING id, created_at
-	`
-	return r.db.QueryRow(
-		query,
+	`,
 		o.UserID,
 		o.CarID,
 		o.TotalPrice,
+		o.Currency,
 		o.Source,
-	).Scan(&o.ID, &o.CreatedAt)
+	).Scan(&o.ID, &o.CreatedAt); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
 }
 
 func (r *OrderRepository) GetByUser(userID int64) ([]model.Order, error) {
 	rows, err := r.db.Query(`
-		SELECT id, user_id, car_id, total_price, source, created_at
+		SELECT id, user_id, car_id, total_price, currency, source, created_at
 		FROM orders
 		WHERE user_id = $1
 		ORDER BY created_at DESC
@@ -49,6 +81,7 @@ func (r *OrderRepository) GetByUser(userID int64) ([]model.Order, error) {
 			&o.UserID,
 			&o.CarID,
 			&o.TotalPrice,
+			&o.Currency,
 			&o.Source,
 			&o.CreatedAt,
 		); err != nil {
@@ -68,3 +101,30 @@ func (r *OrderRepository) ExistsByCarID(carID int64) (bool, error) {
 	`, carID).Scan(&exists)
 	return exists, err
 }
+
+// GetByCarID — заказ на машину; у машины он может быть только один
+func (r *OrderRepository) GetByCarID(carID int64) (*model.Order, error) {
+	var o model.Order
+	err := r.db.QueryRow(`
+		SELECT id, user_id, car_id, total_price, currency, source, created_at
+		FROM orders
+		WHERE car_id = $1
+		ORDER BY id
+		LIMIT 1
+	`, carID).Scan(
+		&o.ID,
+		&o.UserID,
+		&o.CarID,
+		&o.TotalPrice,
+		&o.Currency,
+		&o.Source,
+		&o.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
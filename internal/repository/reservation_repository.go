@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"car-store/internal/model"
+)
+
+type ReservationRepository struct {
+	db *sql.DB
+}
+
+func NewReservationRepository(db *sql.DB) *ReservationRepository {
+	return &ReservationRepository{db: db}
+}
+
+func (r *ReservationRepository) Create(res *model.Reservation) error {
+	query := `
+		INSERT INTO reservations (car_id, user_id, deposit, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	return r.db.QueryRow(
+		query,
+		res.CarID,
+		res.UserID,
+		res.Deposit,
+		res.Status,
+		res.ExpiresAt,
+	).Scan(&res.ID, &res.CreatedAt)
+}
+
+func (r *ReservationRepository) GetActiveByCarID(carID int64) (*model.Reservation, error) {
+	var res model.Reservation
+	err := r.db.QueryRow(`
+		SELECT id, car_id, user_id, deposit, status, expires_at, created_at
+		FROM reservations
+		WHERE car_id = $1 AND status = 'active'
+	`, carID).Scan(
+		&res.ID,
+		&res.CarID,
+		&res.UserID,
+		&res.Deposit,
+		&res.Status,
+		&res.ExpiresAt,
+		&res.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+func (r *ReservationRepository) GetByUser(userID int64) ([]model.Reservation, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, user_id, deposit, status, expires_at, created_at
+		FROM reservations
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReservations(rows)
+}
+
+// GetLapsed — активные брони, у которых истёк срок
+func (r *ReservationRepository) GetLapsed(now time.Time) ([]model.Reservation, error) {
+	rows, err := r.db.Query(`
+		SELECT id, car_id, user_id, deposit, status, expires_at, created_at
+		FROM reservations
+		WHERE status = 'active' AND expires_at <= $1
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanReservations(rows)
+}
+
+// SetStatus меняет статус только активной брони.
+// Возвращает false, если бронь уже не активна.
+func (r *ReservationRepository) SetStatus(id int64, status string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE reservations SET status = $1
+		WHERE id = $2 AND status = 'active'
+	`, status, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func scanReservations(rows *sql.Rows) ([]model.Reservation, error) {
+	var list []model.Reservation
+	for rows.Next() {
+		var res model.Reservation
+		if err := rows.Scan(
+			&res.ID,
+			&res.CarID,
+			&res.UserID,
+			&res.Deposit,
+			&res.Status,
+			&res.ExpiresAt,
+			&res.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		list = append(list, res)
+	}
+	return list, rows.Err()
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+
+	"car-store/internal/model"
+
+	"github.com/lib/pq"
+)
+
+type ShowroomRepository struct {
+	db *sql.DB
+}
+
+func NewShowroomRepository(db *sql.DB) *ShowroomRepository {
+	return &ShowroomRepository{db: db}
+}
+
+const showroomColumns = `id, name, city, address, latitude, longitude, working_hours, created_at`
+
+func scanShowroom(row rowScanner, s *model.Showroom) error {
+	var hours []byte
+	if err := row.Scan(
+		&s.ID,
+		&s.Name,
+		&s.City,
+		&s.Address,
+		&s.Latitude,
+		&s.Longitude,
+		&hours,
+		&s.CreatedAt,
+	); err != nil {
+		return err
+	}
+	return json.Unmarshal(hours, &s.WorkingHours)
+}
+
+func (r *ShowroomRepository) Create(s *model.Showroom) error {
+	hours, err := json.Marshal(s.WorkingHours)
+	if err != nil {
+		return err
+	}
+	return r.db.QueryRow(`
+		INSERT INTO showrooms (name, city, address, latitude, longitude, working_hours)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, s.Name, s.City, s.Address, s.Latitude, s.Longitude, hours).Scan(&s.ID, &s.CreatedAt)
+}
+
+func (r *ShowroomRepository) Update(s *model.Showroom) (bool, error) {
+	hours, err := json.Marshal(s.WorkingHours)
+	if err != nil {
+		return false, err
+	}
+	res, err := r.db.Exec(`
+		UPDATE showrooms
+		SET name=$1, city=$2, address=$3, latitude=$4, longitude=$5, working_hours=$6
+		WHERE id=$7
+	`, s.Name, s.City, s.Address, s.Latitude, s.Longitude, hours, s.ID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+func (r *ShowroomRepository) GetByID(id int64) (*model.Showroom, error) {
+	var s model.Showroom
+	err := scanShowroom(r.db.QueryRow(`SELECT `+showroomColumns+` FROM showrooms WHERE id = $1`, id), &s)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetAll — все салоны; city != "" — только в этом городе (без учёта регистра)
+func (r *ShowroomRepository) GetAll(city string) ([]model.Showroom, error) {
+	rows, err := r.db.Query(`
+		SELECT `+showroomColumns+` FROM showrooms
+		WHERE $1 = '' OR LOWER(city) = LOWER($1)
+		ORDER BY city, name, id
+	`, city)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []model.Showroom
+	for rows.Next() {
+		var s model.Showroom
+		if err := scanShowroom(rows, &s); err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+	}
+	return list, rows.Err()
+}
+
+// GetAdminShowroomIDs — салоны, которыми ограничен админ; пусто — все салоны
+func (r *ShowroomRepository) GetAdminShowroomIDs(userID int64) ([]int64, error) {
+	rows, err := r.db.Query(`
+		SELECT showroom_id FROM admin_showrooms
+		WHERE user_id = $1
+		ORDER BY showroom_id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetAdminShowroomIDs заменяет список салонов админа целиком
+func (r *ShowroomRepository) SetAdminShowroomIDs(userID int64, showroomIDs []int64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM admin_showrooms WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if len(showroomIDs) > 0 {
+		if _, err := tx.Exec(`
+			INSERT INTO admin_showrooms (user_id, showroom_id)
+			SELECT $1, unnest($2::bigint[])
+			ON CONFLICT DO NOTHING
+		`, userID, pq.Array(showroomIDs)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,234 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+
+	"car-store/internal/model"
+)
+
+const testDriveColumns = `id, car_id, user_id, showroom_id, starts_at, ends_at, status, reason, created_at, updated_at`
+
+// пересечение с другой активной записью той же машины или того же пользователя
+const testDriveOverlap = `
+	SELECT 1 FROM test_drives o
+	WHERE o.status = 'booked'
+	  AND o.id <> $1
+	  AND (o.car_id = $2 OR o.user_id = $3)
+	  AND o.starts_at < $5 AND o.ends_at > $4
+`
+
+type TestDriveRepository struct {
+	db *sql.DB
+}
+
+func NewTestDriveRepository(db *sql.DB) *TestDriveRepository {
+	return &TestDriveRepository{db: db}
+}
+
+func scanTestDrive(row rowScanner, td *model.TestDrive) error {
+	return row.Scan(
+		&td.ID,
+		&td.CarID,
+		&td.UserID,
+		&td.ShowroomID,
+		&td.StartsAt,
+		&td.EndsAt,
+		&td.Status,
+		&td.Reason,
+		&td.CreatedAt,
+		&td.UpdatedAt,
+	)
+}
+
+func scanTestDrives(rows *sql.Rows) ([]model.TestDrive, error) {
+	var list []model.TestDrive
+	for rows.Next() {
+		var td model.TestDrive
+		if err := scanTestDrive(rows, &td); err != nil {
+			return nil, err
+		}
+		list = append(list, td)
+	}
+	return list, rows.Err()
+}
+
+// isUniqueViolation — параллельная запись заняла тот же слот раньше нас
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// Create записывает тест-драйв, только если машина и пользователь свободны
+// в это время. Возвращает false, если слот уже занят.
+func (r *TestDriveRepository) Create(td *model.TestDrive) (bool, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO test_drives (car_id, user_id, showroom_id, starts_at, ends_at, status)
+		SELECT $2::bigint, $3::bigint, $6::bigint, $4::timestamp, $5::timestamp, 'booked'
+		WHERE NOT EXISTS (`+testDriveOverlap+`)
+		ON CONFLICT DO NOTHING
+		RETURNING id, status, created_at, updated_at
+	`,
+		0,
+		td.CarID,
+		td.UserID,
+		td.StartsAt,
+		td.EndsAt,
+		td.ShowroomID,
+	).Scan(&td.ID, &td.Status, &td.CreatedAt, &td.UpdatedAt)
+	if err == sql.ErrNoRows || isUniqueViolation(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Conflicts сообщает, занята ли машина и есть ли у пользователя другая запись
+// в интервале [startsAt, endsAt). Запись excludeID не учитывается.
+func (r *TestDriveRepository) Conflicts(carID, userID int64, startsAt, endsAt time.Time, excludeID int64) (carBusy, userBusy bool, err error) {
+	err = r.db.QueryRow(`
+		SELECT
+			EXISTS (SELECT 1 FROM test_drives o
+			        WHERE o.status = 'booked' AND o.id <> $1 AND o.car_id = $2
+			          AND o.starts_at < $5 AND o.ends_at > $4),
+			EXISTS (SELECT 1 FROM test_drives o
+			        WHERE o.status = 'booked' AND o.id <> $1 AND o.user_id = $3
+			          AND o.starts_at < $5 AND o.ends_at > $4)
+	`, excludeID, carID, userID, startsAt, endsAt).Scan(&carBusy, &userBusy)
+	return carBusy, userBusy, err
+}
+
+func (r *TestDriveRepository) GetByID(id int64) (*model.TestDrive, error) {
+	var td model.TestDrive
+	err := scanTestDrive(r.db.QueryRow(`
+		SELECT `+testDriveColumns+`
+		FROM test_drives
+		WHERE id = $1
+	`, id), &td)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &td, nil
+}
+
+func (r *TestDriveRepository) GetByUser(userID int64) ([]model.TestDrive, error) {
+	rows, err := r.db.Query(`
+		SELECT `+testDriveColumns+`
+		FROM test_drives
+		WHERE user_id = $1
+		ORDER BY starts_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTestDrives(rows)
+}
+
+// GetBookedByCar — активные записи на машину, пересекающие [from, to)
+func (r *TestDriveRepository) GetBookedByCar(carID int64, from, to time.Time) ([]model.TestDrive, error) {
+	rows, err := r.db.Query(`
+		SELECT `+testDriveColumns+`
+		FROM test_drives
+		WHERE car_id = $1 AND status = 'booked'
+		  AND starts_at < $3 AND ends_at > $2
+		ORDER BY starts_at
+	`, carID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTestDrives(rows)
+}
+
+// GetCalendar — активные записи с началом в [from, to).
+// Пустой showroomIDs — все салоны.
+func (r *TestDriveRepository) GetCalendar(from, to time.Time, showroomIDs []int64) ([]model.TestDrive, error) {
+	rows, err := r.db.Query(`
+		SELECT `+testDriveColumns+`
+		FROM test_drives
+		WHERE status = 'booked'
+		  AND starts_at >= $1 AND starts_at < $2
+		  AND (cardinality($3::bigint[]) = 0 OR showroom_id = ANY($3))
+		ORDER BY starts_at, showroom_id, id
+	`, from, to, pq.Array(showroomIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTestDrives(rows)
+}
+
+// Reschedule переносит активную запись, если новый слот свободен.
+// Возвращает false, если запись уже не активна или слот занят.
+func (r *TestDriveRepository) Reschedule(td *model.TestDrive) (bool, error) {
+	err := r.db.QueryRow(`
+		UPDATE test_drives t
+		SET starts_at = $4, ends_at = $5, updated_at = NOW()
+		WHERE t.id = $1 AND t.status = 'booked'
+		  AND NOT EXISTS (`+testDriveOverlap+`)
+		RETURNING updated_at
+	`,
+		td.ID,
+		td.CarID,
+		td.UserID,
+		td.StartsAt,
+		td.EndsAt,
+	).Scan(&td.UpdatedAt)
+	if err == sql.ErrNoRows || isUniqueViolation(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// SetStatus закрывает только активную запись.
+// Возвращает false, если запись уже отменена или отклонена.
+func (r *TestDriveRepository) SetStatus(id int64, status, reason string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE test_drives SET status = $1, reason = $2, updated_at = NOW()
+		WHERE id = $3 AND status = 'booked'
+	`, status, reason, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RejectForUnavailableCars одним запросом отклоняет будущие записи на машины,
+// которые проданы или выставлены на аукцион, и возвращает отклонённые.
+func (r *TestDriveRepository) RejectForUnavailableCars(now time.Time) ([]model.TestDrive, error) {
+	rows, err := r.db.Query(`
+		UPDATE test_drives t
+		SET status = 'rejected',
+		    reason = CASE c.status WHEN 'sold' THEN 'car has been sold' ELSE 'car has been put up for auction' END,
+		    updated_at = NOW()
+		FROM cars c
+		WHERE c.id = t.car_id
+		  AND t.status = 'booked'
+		  AND t.ends_at > $1
+		  AND c.status IN ('sold', 'on_auction')
+		RETURNING t.id, t.car_id, t.user_id, t.showroom_id, t.starts_at, t.ends_at,
+		          t.status, t.reason, t.created_at, t.updated_at
+	`, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanTestDrives(rows)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"car-store/internal/model"
+	"car-store/internal/money"
 )
 
 type TradeInRepository interface {
@@ -15,8 +16,8 @@ type TradeInRepository interface {
 	GetByUserID(ctx context.Context, userID int64) ([]model.TradeIn, error)
 	GetAll(ctx context.Context, status string) ([]model.TradeIn, error)
 	UpdateStatus(ctx context.Context, id int64, status string) error
-	Evaluate(ctx context.Context, id int64, estimatedPrice float64) error
-	SetUserPayment(ctx context.Context, id int64, userPayment float64, kolesaURL string) error
+	Evaluate(ctx context.Context, id int64, estimatedPrice money.Money) error
+	SetUserPayment(ctx context.Context, id int64, userPayment money.Money, kolesaURL string) error
 	Delete(ctx context.Context, id int64) error
 }
 
@@ -199,7 +200,7 @@ func (r *tradeInRepository) UpdateStatus(ctx context.Context, id int64, status s
 	return nil
 }
 
-func (r *tradeInRepository) Evaluate(ctx context.Context, id int64, estimatedPrice float64) error {
+func (r *tradeInRepository) Evaluate(ctx context.Context, id int64, estimatedPrice money.Money) error {
 	query := `
 		UPDATE tradeins
 		SET estimated_price = $1, status = 'evaluated'
@@ -224,7 +225,7 @@ func (r *tradeInRepository) Evaluate(ctx context.Context, id int64, estimatedPri
 }
 
 // SetUserPayment - юзер указывает сколько готов доплатить, генерируется ссылка на kolesa.kz
-func (r *tradeInRepository) SetUserPayment(ctx context.Context, id int64, userPayment float64, kolesaURL string) error {
+func (r *tradeInRepository) SetUserPayment(ctx context.Context, id int64, userPayment money.Money, kolesaURL string) error {
 	query := `
 		UPDATE tradeins
 		SET status = 'accepted'
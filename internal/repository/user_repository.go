@@ -30,13 +30,14 @@ func (r *UserRepository) Create(u *model.User) error {
 func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 	var u model.User
 	err := r.db.QueryRow(`
-		SELECT id, email, password_hash, role, created_at
+		SELECT id, email, password_hash, role, COALESCE(preferred_currency, ''), created_at
 		FROM users WHERE email = $1
 	`, email).Scan(
 		&u.ID,
 		&u.Email,
 		&u.PasswordHash,
 		&u.Role,
+		&u.PreferredCurrency,
 		&u.CreatedAt,
 	)
 	if err == sql.ErrNoRows {
@@ -44,3 +45,35 @@ func (r *UserRepository) GetByEmail(email string) (*model.User, error) {
 	}
 	return &u, err
 }
+
+func (r *UserRepository) GetByID(id int64) (*model.User, error) {
+	var u model.User
+	err := r.db.QueryRow(`
+		SELECT id, email, password_hash, role, COALESCE(preferred_currency, ''), created_at
+		FROM users WHERE id = $1
+	`, id).Scan(
+		&u.ID,
+		&u.Email,
+		&u.PasswordHash,
+		&u.Role,
+		&u.PreferredCurrency,
+		&u.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return &u, err
+}
+
+// SetPreferredCurrency — пустая строка сбрасывает предпочтение
+func (r *UserRepository) SetPreferredCurrency(userID int64, currency string) (bool, error) {
+	res, err := r.db.Exec(`
+		UPDATE users SET preferred_currency = NULLIF($2, '')
+		WHERE id = $1
+	`, userID, currency)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
@@ -0,0 +1,397 @@
+package service_test
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"car-store/internal/event"
+	"car-store/internal/model"
+	"car-store/internal/money"
+	"car-store/internal/repository"
+	"car-store/internal/service"
+
+	_ "github.com/lib/pq"
+)
+
+// Нагрузочные тесты ставок идут против настоящего PostgreSQL: инвариант
+// держится на блокировке строки аукциона, и заглушка его не проверит.
+// Строка подключения — в CARSTORE_TEST_DATABASE_URL; схема создаётся
+// во временной schema и удаляется после теста.
+const testDatabaseEnv = "CARSTORE_TEST_DATABASE_URL"
+
+const (
+	stressStartPrice = 1000
+	stressStep       = 10
+)
+
+type allowAll struct{}
+
+func (allowAll) CanManage(int64, *int64) (bool, error) { return true, nil }
+
+type silentNotifier struct{}
+
+func (silentNotifier) Notify(int64, string, string) {}
+
+// withSearchPath добавляет к строке подключения search_path
+func withSearchPath(dsn, schema string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		q.Set("search_path", schema)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	return dsn + " search_path=" + schema, nil
+}
+
+// openTestDB создаёт отдельную schema со схемой приложения
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseEnv)
+	}
+
+	admin, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { admin.Close() })
+
+	schema := fmt.Sprintf("bid_stress_%d", time.Now().UnixNano())
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if _, err := admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`); err != nil {
+			t.Log("drop test schema:", err)
+		}
+	})
+
+	scoped, err := withSearchPath(dsn, schema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := sql.Open("postgres", scoped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ddl, err := os.ReadFile("../../db/schema.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec(string(ddl)); err != nil {
+		t.Fatal("apply schema:", err)
+	}
+	return db
+}
+
+func newStressService(db *sql.DB) *service.AuctionService {
+	carRepo := repository.NewCarRepository(db)
+	bus := event.NewBus()
+	orders := service.NewOrderService(
+		repository.NewOrderRepository(db),
+		carRepo,
+		repository.NewReservationRepository(db),
+		bus,
+	)
+	return service.NewAuctionService(
+		repository.NewAuctionRepository(db),
+		carRepo,
+		repository.NewBidRepository(db),
+		orders,
+		allowAll{},
+		silentNotifier{},
+		bus,
+		model.SoftClose{}, // без продления: конец аукциона не должен влиять на итог
+		[]model.BidIncrement{{Step: money.FromUnits(stressStep)}},
+		50,
+		time.Hour,
+	)
+}
+
+// createUsers добавляет n пользователей и возвращает их id
+func createUsers(t *testing.T, db *sql.DB, n int) []int64 {
+	t.Helper()
+	ids := make([]int64, n)
+	for i := range ids {
+		if err := db.QueryRow(`
+			INSERT INTO users (email, role) VALUES ($1, 'user') RETURNING id
+		`, fmt.Sprintf("bidder-%d-%d@test", time.Now().UnixNano(), i)).Scan(&ids[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ids
+}
+
+// createActiveAuction — уже идущий аукцион на отдельной машине
+func createActiveAuction(t *testing.T, db *sql.DB) int64 {
+	t.Helper()
+	var carID, auctionID int64
+	if err := db.QueryRow(`
+		INSERT INTO cars (brand, model, year, price, status)
+		VALUES ('Toyota', 'Camry', 2020, 10000, 'on_auction')
+		RETURNING id
+	`).Scan(&carID); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.QueryRow(`
+		INSERT INTO auctions (car_id, start_price, start_time, end_time, scheduled_end_time, status)
+		VALUES ($1, $2, NOW() - INTERVAL '1 day', NOW() + INTERVAL '1 day', NOW() + INTERVAL '1 day', 'active')
+		RETURNING id
+	`, carID, money.FromUnits(stressStartPrice)).Scan(&auctionID); err != nil {
+		t.Fatal(err)
+	}
+	return auctionID
+}
+
+type bidOutcome struct {
+	accepted, tooLow, rateLimited int
+}
+
+type bidAttempt struct {
+	userID int64
+	amount money.Money
+}
+
+// placeConcurrently отправляет все ставки одновременно и считает исходы
+func placeConcurrently(t *testing.T, s *service.AuctionService, auctionID int64, attempts []bidAttempt) bidOutcome {
+	t.Helper()
+
+	var (
+		mu    sync.Mutex
+		out   bidOutcome
+		wg    sync.WaitGroup
+		start = make(chan struct{})
+	)
+	for _, a := range attempts {
+		wg.Add(1)
+		go func(a bidAttempt) {
+			defer wg.Done()
+			<-start
+			err := s.PlaceBid(auctionID, a.userID, a.amount)
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				out.accepted++
+			case errors.Is(err, service.ErrBidTooLow):
+				out.tooLow++
+			case errors.Is(err, service.ErrBidRateLimited):
+				out.rateLimited++
+			default:
+				t.Errorf("unexpected error for %s from user %d: %v", a.amount, a.userID, err)
+			}
+		}(a)
+	}
+	close(start)
+	wg.Wait()
+	return out
+}
+
+// storedBids — суммы ставок в порядке записи
+func storedBids(t *testing.T, db *sql.DB, auctionID int64) []money.Money {
+	t.Helper()
+	rows, err := db.Query(`SELECT amount FROM bids WHERE auction_id = $1 ORDER BY id`, auctionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var amounts []money.Money
+	for rows.Next() {
+		var m money.Money
+		if err := rows.Scan(&m); err != nil {
+			t.Fatal(err)
+		}
+		amounts = append(amounts, m)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return amounts
+}
+
+func assertStrictlyIncreasing(t *testing.T, amounts []money.Money) {
+	t.Helper()
+	for i := 1; i < len(amounts); i++ {
+		if amounts[i] <= amounts[i-1] {
+			t.Fatalf("bid #%d (%s) is not above bid #%d (%s): %v", i, amounts[i], i-1, amounts[i-1], amounts)
+		}
+	}
+}
+
+func TestConcurrentBidsEqualAndIncreasingAmounts(t *testing.T) {
+	db := openTestDB(t)
+	s := newStressService(db)
+	auctionID := createActiveAuction(t, db)
+
+	const (
+		rounds          = 5
+		biddersPerRound = 20
+	)
+
+	// в каждом раунде biddersPerRound разных пользователей ставят одну и ту же
+	// сумму, минимально допустимую на начало раунда: принимается ровно одна
+	amount := money.FromUnits(stressStartPrice)
+	var want []money.Money
+	for round := 0; round < rounds; round++ {
+		users := createUsers(t, db, biddersPerRound)
+		attempts := make([]bidAttempt, len(users))
+		for i, u := range users {
+			attempts[i] = bidAttempt{userID: u, amount: amount}
+		}
+
+		out := placeConcurrently(t, s, auctionID, attempts)
+		if out.accepted != 1 || out.tooLow != biddersPerRound-1 || out.rateLimited != 0 {
+			t.Fatalf("round %d (%s): %+v, want 1 accepted and %d too low", round, amount, out, biddersPerRound-1)
+		}
+
+		want = append(want, amount)
+		amount += money.FromUnits(stressStep)
+	}
+
+	got := storedBids(t, db, auctionID)
+	if len(got) != len(want) {
+		t.Fatalf("stored %d bids, want %d: %v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("stored bids = %v, want %v", got, want)
+		}
+	}
+	assertStrictlyIncreasing(t, got)
+}
+
+func TestConcurrentBidsMixedAmountsOnlyGoUp(t *testing.T) {
+	db := openTestDB(t)
+	s := newStressService(db)
+	auctionID := createActiveAuction(t, db)
+
+	// по две ставки каждой суммы от разных пользователей, все суммы — разом:
+	// порядок записи произвольный, но каждая принятая ставка выше предыдущей,
+	// и одна сумма не может быть принята дважды
+	const levels = 15
+	users := createUsers(t, db, 2*levels)
+	var attempts []bidAttempt
+	for i := 0; i < levels; i++ {
+		amount := money.FromUnits(stressStartPrice + int64(i)*stressStep)
+		attempts = append(attempts,
+			bidAttempt{userID: users[2*i], amount: amount},
+			bidAttempt{userID: users[2*i+1], amount: amount},
+		)
+	}
+
+	out := placeConcurrently(t, s, auctionID, attempts)
+	if out.accepted < 1 || out.accepted+out.tooLow != len(attempts) || out.rateLimited != 0 {
+		t.Fatalf("outcome %+v for %d attempts", out, len(attempts))
+	}
+
+	got := storedBids(t, db, auctionID)
+	if len(got) != out.accepted {
+		t.Fatalf("stored %d bids, %d accepted", len(got), out.accepted)
+	}
+	assertStrictlyIncreasing(t, got)
+	// последняя сумма выше всех остальных и принимается всегда
+	if top := money.FromUnits(stressStartPrice + (levels-1)*stressStep); got[len(got)-1] != top {
+		t.Fatalf("highest stored bid = %s, want %s", got[len(got)-1], top)
+	}
+}
+
+func TestConcurrentBidsRespectRateLimit(t *testing.T) {
+	db := openTestDB(t)
+	s := newStressService(db)
+
+	const parallel = 12
+
+	t.Run("limit reached before the burst", func(t *testing.T) {
+		auctionID := createActiveAuction(t, db)
+		user := createUsers(t, db, 1)[0]
+
+		// три ставки подряд исчерпывают лимит
+		amount := money.FromUnits(stressStartPrice)
+		for i := 0; i < 3; i++ {
+			if err := s.PlaceBid(auctionID, user, amount); err != nil {
+				t.Fatalf("warm-up bid %d: %v", i, err)
+			}
+			amount += money.FromUnits(stressStep)
+		}
+
+		// лимит проверяется раньше суммы — ни одна ставка не должна пройти
+		attempts := make([]bidAttempt, parallel)
+		for i := range attempts {
+			attempts[i] = bidAttempt{userID: user, amount: amount + money.FromUnits(int64(i)*stressStep)}
+		}
+		out := placeConcurrently(t, s, auctionID, attempts)
+		if out.accepted != 0 || out.rateLimited != parallel {
+			t.Fatalf("outcome %+v, want all %d rate limited", out, parallel)
+		}
+		if got := storedBids(t, db, auctionID); len(got) != 3 {
+			t.Fatalf("stored %d bids, want 3", len(got))
+		}
+	})
+
+	t.Run("burst from one user", func(t *testing.T) {
+		auctionID := createActiveAuction(t, db)
+		user := createUsers(t, db, 1)[0]
+
+		// одновременные ставки одного пользователя: пройти могут не больше трёх
+		attempts := make([]bidAttempt, parallel)
+		for i := range attempts {
+			attempts[i] = bidAttempt{userID: user, amount: money.FromUnits(stressStartPrice + int64(i)*stressStep)}
+		}
+		out := placeConcurrently(t, s, auctionID, attempts)
+		if out.accepted < 1 || out.accepted > 3 || out.accepted+out.tooLow+out.rateLimited != parallel {
+			t.Fatalf("outcome %+v, want 1..3 accepted", out)
+		}
+
+		got := storedBids(t, db, auctionID)
+		if len(got) != out.accepted {
+			t.Fatalf("stored %d bids, %d accepted", len(got), out.accepted)
+		}
+		assertStrictlyIncreasing(t, got)
+	})
+
+	t.Run("limit is per user", func(t *testing.T) {
+		auctionID := createActiveAuction(t, db)
+		users := createUsers(t, db, 2)
+
+		// у каждого по parallel ставок с чередующимися суммами
+		var attempts []bidAttempt
+		for i := 0; i < parallel; i++ {
+			attempts = append(attempts, bidAttempt{
+				userID: users[i%2],
+				amount: money.FromUnits(stressStartPrice + int64(i)*stressStep),
+			})
+		}
+		out := placeConcurrently(t, s, auctionID, attempts)
+		if out.accepted > 6 {
+			t.Fatalf("outcome %+v, want at most 3 accepted per user", out)
+		}
+
+		var perUser [2]int
+		if err := db.QueryRow(`
+			SELECT COUNT(*) FILTER (WHERE user_id = $2), COUNT(*) FILTER (WHERE user_id = $3)
+			FROM bids WHERE auction_id = $1
+		`, auctionID, users[0], users[1]).Scan(&perUser[0], &perUser[1]); err != nil {
+			t.Fatal(err)
+		}
+		if perUser[0] > 3 || perUser[1] > 3 {
+			t.Fatalf("bids per user = %v, want at most 3 each", perUser)
+		}
+		assertStrictlyIncreasing(t, storedBids(t, db, auctionID))
+	})
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"sync"
+
+	"car-store/internal/model"
+)
+
+// размер очереди обновлений на одного подписчика
+const auctionSubscriberBuffer = 32
+
+// AuctionHub рассылает обновления аукционов подписчикам (SSE, WebSocket).
+// Публикация никогда не блокируется: у каждого подписчика своя очередь,
+// и если клиент не успевает её разбирать, подписка закрывается с Lagged() == true.
+type AuctionHub struct {
+	mu     sync.Mutex
+	subs   map[int64]map[*AuctionSubscription]struct{}
+	buffer int
+}
+
+func NewAuctionHub(buffer int) *AuctionHub {
+	return &AuctionHub{
+		subs:   make(map[int64]map[*AuctionSubscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+type AuctionSubscription struct {
+	hub       *AuctionHub
+	auctionID int64
+	ch        chan model.AuctionUpdate
+	lagged    bool // пишется и читается под hub.mu
+}
+
+// Updates закрывается, когда аукцион завершён или удалён, подписчик отстал
+// или вызван Close
+func (s *AuctionSubscription) Updates() <-chan model.AuctionUpdate {
+	return s.ch
+}
+
+// Lagged — подписка закрыта из-за переполнения очереди
+func (s *AuctionSubscription) Lagged() bool {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.lagged
+}
+
+func (s *AuctionSubscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.removeLocked(s)
+}
+
+func (h *AuctionHub) Subscribe(auctionID int64) *AuctionSubscription {
+	sub := &AuctionSubscription{
+		hub:       h,
+		auctionID: auctionID,
+		ch:        make(chan model.AuctionUpdate, h.buffer),
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.subs[auctionID] == nil {
+		h.subs[auctionID] = make(map[*AuctionSubscription]struct{})
+	}
+	h.subs[auctionID][sub] = struct{}{}
+	return sub
+}
+
+func (h *AuctionHub) Publish(u model.AuctionUpdate) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[u.AuctionID] {
+		select {
+		case sub.ch <- u:
+		default:
+			sub.lagged = true
+			h.removeLocked(sub)
+		}
+	}
+}
+
+// CloseAuction закрывает все подписки аукциона — после финального события
+func (h *AuctionHub) CloseAuction(auctionID int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for sub := range h.subs[auctionID] {
+		h.removeLocked(sub)
+	}
+}
+
+// removeLocked — канал закрывается ровно один раз: только пока подписка в карте
+func (h *AuctionHub) removeLocked(sub *AuctionSubscription) {
+	subs := h.subs[sub.auctionID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(h.subs, sub.auctionID)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+var (
+	ErrOfferNotFound   = errors.New("offer not found")
+	ErrOfferNotAllowed = errors.New("offer is not allowed for this auction")
+	ErrInvalidOffer    = errors.New("invalid offer")
+	ErrOfferPending    = errors.New("an offer for this auction is already under review")
+	ErrOfferDecided    = errors.New("offer has already been decided")
+)
+
+type AuctionOfferRepo interface {
+	Create(o *model.AuctionOffer) (bool, error)
+	GetByID(id int64) (*model.AuctionOffer, error)
+	GetByUser(userID int64) ([]model.AuctionOffer, error)
+	GetAll(status string) ([]model.AuctionOffer, error)
+	SetStatus(id int64, from, to string, decidedBy *int64) (bool, error)
+}
+
+type AuctionGetter interface {
+	GetByID(id int64) (*model.Auction, error)
+}
+
+type MaxBidGetter interface {
+	GetMaxBidByAuctionID(auctionID int64) (*model.Bid, error)
+}
+
+// AuctionOfferService — переговоры после аукциона, закрытого ниже резервной цены:
+// лидер предлагает цену, админ салона принимает или отклоняет её
+type AuctionOfferService struct {
+	repo     AuctionOfferRepo
+	auctions AuctionGetter
+	bids     MaxBidGetter
+	cars     CarGetter
+	orders   OrderCreator
+	access   ShowroomAccess
+	notifier Notifier
+}
+
+func NewAuctionOfferService(
+	repo AuctionOfferRepo,
+	auctions AuctionGetter,
+	bids MaxBidGetter,
+	cars CarGetter,
+	orders OrderCreator,
+	access ShowroomAccess,
+	notifier Notifier,
+) *AuctionOfferService {
+	return &AuctionOfferService{
+		repo:     repo,
+		auctions: auctions,
+		bids:     bids,
+		cars:     cars,
+		orders:   orders,
+		access:   access,
+		notifier: notifier,
+	}
+}
+
+// MakeOffer — предложить цену может только лидер закончившегося аукциона,
+// не дошедшего до резерва, пока машина не продана и не выставлена снова.
+// Предложение не может быть ниже его последней ставки.
+func (s *AuctionOfferService) MakeOffer(userID, auctionID int64, amount money.Money) (*model.AuctionOffer, error) {
+	a, err := s.auctions.GetByID(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAuctionNotFound
+	}
+	if a.Status != model.AuctionStatusNoSale {
+		return nil, fmt.Errorf("%w: auction is still running or has been sold", ErrOfferNotAllowed)
+	}
+	if a.ReserveMet == nil || *a.ReserveMet {
+		return nil, fmt.Errorf("%w: auction did not end below reserve price", ErrOfferNotAllowed)
+	}
+
+	top, err := s.bids.GetMaxBidByAuctionID(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if top == nil || top.UserID != userID {
+		return nil, fmt.Errorf("%w: only the top bidder can make an offer", ErrOfferNotAllowed)
+	}
+	if amount < top.Amount {
+		return nil, fmt.Errorf("%w: offer must not be below your top bid of %s", ErrInvalidOffer, top.Amount)
+	}
+
+	car, err := s.cars.GetByID(a.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil || car.DeletedAt != nil || car.Status != model.CarStatusAvailable {
+		return nil, fmt.Errorf("%w: car is no longer available", ErrOfferNotAllowed)
+	}
+
+	o := &model.AuctionOffer{
+		AuctionID: auctionID,
+		UserID:    userID,
+		Amount:    amount,
+	}
+	ok, err := s.repo.Create(o)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOfferPending
+	}
+	return o, nil
+}
+
+func (s *AuctionOfferService) GetMyOffers(userID int64) ([]model.AuctionOffer, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *AuctionOfferService) GetOffers(status string) ([]model.AuctionOffer, error) {
+	return s.repo.GetAll(status)
+}
+
+// AcceptOffer продаёт машину лидеру по предложенной цене тем же путём,
+// что и победу в аукционе
+func (s *AuctionOfferService) AcceptOffer(offerID, adminID int64) (*model.AuctionOffer, error) {
+	o, a, car, err := s.pendingOffer(offerID, adminID)
+	if err != nil {
+		return nil, err
+	}
+	// машину могли забронировать или выставить снова, пока предложение ждало
+	if car.DeletedAt != nil || car.Status != model.CarStatusAvailable {
+		return nil, fmt.Errorf("%w: car is no longer available", ErrOfferNotAllowed)
+	}
+
+	// сначала занимаем предложение, чтобы два админа не приняли его одновременно
+	ok, err := s.repo.SetStatus(o.ID, model.AuctionOfferPending, model.AuctionOfferAccepted, &adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOfferDecided
+	}
+
+	if err := s.orders.CreateFromAuction(o.UserID, a.CarID, o.Amount); err != nil {
+		// заказ не создан — предложение снова ждёт решения
+		_, _ = s.repo.SetStatus(o.ID, model.AuctionOfferAccepted, model.AuctionOfferPending, nil)
+		return nil, err
+	}
+
+	s.notifier.Notify(
+		o.UserID,
+		"auction_offer_accepted",
+		fmt.Sprintf("Your offer of %s for auction %d was accepted, the order has been created", o.Amount, a.ID),
+	)
+	return s.repo.GetByID(o.ID)
+}
+
+func (s *AuctionOfferService) RejectOffer(offerID, adminID int64) (*model.AuctionOffer, error) {
+	o, a, _, err := s.pendingOffer(offerID, adminID)
+	if err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.SetStatus(o.ID, model.AuctionOfferPending, model.AuctionOfferRejected, &adminID)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrOfferDecided
+	}
+
+	s.notifier.Notify(
+		o.UserID,
+		"auction_offer_rejected",
+		fmt.Sprintf("Your offer of %s for auction %d was declined", o.Amount, a.ID),
+	)
+	return s.repo.GetByID(o.ID)
+}
+
+// pendingOffer — нерассмотренное предложение по машине из салона админа
+func (s *AuctionOfferService) pendingOffer(offerID, adminID int64) (*model.AuctionOffer, *model.Auction, *model.Car, error) {
+	o, err := s.repo.GetByID(offerID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if o == nil {
+		return nil, nil, nil, ErrOfferNotFound
+	}
+	if o.Status != model.AuctionOfferPending {
+		return nil, nil, nil, ErrOfferDecided
+	}
+
+	a, err := s.auctions.GetByID(o.AuctionID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if a == nil {
+		return nil, nil, nil, ErrAuctionNotFound
+	}
+
+	car, err := s.cars.GetByID(a.CarID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if car == nil {
+		return nil, nil, nil, ErrCarNotFound
+	}
+	if err := checkShowroomAccess(s.access, adminID, car.ShowroomID); err != nil {
+		return nil, nil, nil, err
+	}
+	return o, a, car, nil
+}
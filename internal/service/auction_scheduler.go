@@ -0,0 +1,128 @@
+package service
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// AuctionScheduler — очередь с приоритетом по времени ближайшего события
+// каждого аукциона (начала или конца). Один таймер ждёт самое раннее событие,
+// поэтому аукцион открывается и закрывается точно в срок, без опроса.
+type AuctionScheduler struct {
+	mu    sync.Mutex
+	queue auctionQueue
+	items map[int64]*scheduledAuction
+	// будит Run, когда самое раннее событие поменялось
+	wake chan struct{}
+}
+
+type scheduledAuction struct {
+	auctionID int64
+	at        time.Time
+	index     int
+}
+
+func NewAuctionScheduler() *AuctionScheduler {
+	return &AuctionScheduler{
+		items: make(map[int64]*scheduledAuction),
+		wake:  make(chan struct{}, 1),
+	}
+}
+
+// Schedule ставит событие аукциона на at; уже стоящее в очереди переносится
+func (s *AuctionScheduler) Schedule(auctionID int64, at time.Time) {
+	s.mu.Lock()
+	if item, ok := s.items[auctionID]; ok {
+		item.at = at
+		heap.Fix(&s.queue, item.index)
+	} else {
+		item = &scheduledAuction{auctionID: auctionID, at: at}
+		heap.Push(&s.queue, item)
+		s.items[auctionID] = item
+	}
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+func (s *AuctionScheduler) Remove(auctionID int64) {
+	s.mu.Lock()
+	if item, ok := s.items[auctionID]; ok {
+		heap.Remove(&s.queue, item.index)
+		delete(s.items, auctionID)
+	}
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+func (s *AuctionScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run ждёт наступления событий и для каждого запускает fire в отдельной
+// горутине: долгий расчёт одного аукциона не задерживает остальные.
+// Наступившее событие из очереди убирается — fire сам ставит следующее.
+func (s *AuctionScheduler) Run(fire func(auctionID int64)) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		var due []int64
+		var next <-chan time.Time
+
+		s.mu.Lock()
+		now := time.Now()
+		for len(s.queue) > 0 && !s.queue[0].at.After(now) {
+			item := heap.Pop(&s.queue).(*scheduledAuction)
+			delete(s.items, item.auctionID)
+			due = append(due, item.auctionID)
+		}
+		if len(s.queue) > 0 {
+			timer.Reset(s.queue[0].at.Sub(now))
+			next = timer.C
+		}
+		s.mu.Unlock()
+
+		for _, id := range due {
+			go fire(id)
+		}
+
+		select {
+		case <-next:
+		case <-s.wake:
+		}
+	}
+}
+
+// auctionQueue — heap.Interface, сверху самое раннее событие
+type auctionQueue []*scheduledAuction
+
+func (q auctionQueue) Len() int { return len(q) }
+
+func (q auctionQueue) Less(i, j int) bool { return q[i].at.Before(q[j].at) }
+
+func (q auctionQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *auctionQueue) Push(x any) {
+	item := x.(*scheduledAuction)
+	item.index = len(*q)
+	*q = append(*q, item)
+}
+
+func (q *auctionQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
@@ -4,10 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"car-store/internal/model"
+	"car-store/internal/money"
 )
 
 var ErrAuctionFinished = errors.New("auction is finished")
@@ -15,31 +15,63 @@ var ErrAuctionFinished = errors.New("auction is finished")
 var (
 	ErrCarAlreadyOnAuction = errors.New("car already on auction")
 	ErrCarNotFound         = errors.New("car not found")
+	ErrAuctionNotFound     = errors.New("auction not found")
+	ErrInvalidAuction      = errors.New("invalid auction")
+	ErrBidTooLow           = errors.New("bid is too low")
+	ErrBidRateLimited      = fmt.Errorf("bid limit exceeded: max %d bids per minute", maxBidsPerMinute)
+	ErrProxyBidNotFound    = errors.New("proxy bid not found")
+	ErrInvalidProxyBid     = errors.New("invalid proxy bid")
+	ErrBuyNowUnavailable   = errors.New("buy now is not available for this auction")
+	ErrAuctionNotStarted   = errors.New("auction has not started yet")
+	ErrCarNotAuctionable   = errors.New("car is not available for auction")
 )
 
+// ручных ставок одного пользователя на аукцион в минуту
+const maxBidsPerMinute = 3
+
 // ---------- REPO INTERFACES ----------
 
 type AuctionRepo interface {
 	Create(a *model.Auction) error
 	GetAll() ([]model.Auction, error)
-	Update(a *model.Auction) error
-	Delete(id int64) error
+	Update(a *model.Auction) (bool, error)
+	Cancel(id int64) (bool, error)
 	GetByID(id int64) (*model.Auction, error)
 	ExistsByCarID(carID int64) (bool, error)
+
+	GetOpen() ([]model.Auction, error)
+	GetUpcoming(now time.Time) ([]model.Auction, error)
+	ActivateDue(now time.Time) (int64, error)
+	GetDueForSettlement(now, staleBefore time.Time) ([]model.Auction, error)
+	ClaimSettlement(id int64, now, staleBefore time.Time) (bool, error)
+	FinishSettlement(id int64, status string, now time.Time) (bool, error)
 }
 
 type CarExistenceRepo interface {
 	ExistsByID(id int64) (bool, error)
+	CarStatusRepo
 }
 
 type BidRepo interface {
-	Create(b *model.Bid) error
+	Create(b *model.Bid, rules model.BidRules) (model.BidResult, error)
 	GetMaxBidByAuctionID(auctionID int64) (*model.Bid, error)
-	UserBidsLimitInMinute(userID, auctionID int64) (int, error)
+	GetHistory(auctionID int64) ([]model.BidHistoryItem, error)
+
+	SetProxyBid(p *model.ProxyBid, rules model.BidRules) (model.BidResult, error)
+	GetProxyBid(auctionID, userID int64) (*model.ProxyBid, error)
+	DeleteProxyBid(auctionID, userID int64) (bool, error)
+
+	BuyNow(b *model.Bid, cutoffPercent int) (bool, error)
 }
 
 type OrderCreator interface {
-	CreateFromAuction(userID, carID int64, price float64) error
+	CreateFromAuction(userID, carID int64, price money.Money) error
+}
+
+type AuctionOrders interface {
+	OrderCreator
+	IsCarOrdered(carID int64) (bool, error)
+	GetCarOrder(carID int64) (*model.Order, error)
 }
 
 // ---------- SERVICE ----------
@@ -48,38 +80,81 @@ type AuctionService struct {
 	repo     AuctionRepo
 	carRepo  CarExistenceRepo
 	bidRepo  BidRepo
-	orderSvc OrderCreator
-
-	finished map[int64]bool
-	mu       sync.Mutex
+	orderSvc AuctionOrders
+	access   ShowroomAccess
+	notifier Notifier
+	events   EventPublisher
+	hub      *AuctionHub
+	// ближайшие начала и концы аукционов
+	scheduler *AuctionScheduler
+
+	// правило продления при ставках в последние минуты
+	softClose model.SoftClose
+	// ступени минимального шага ставки
+	increments []model.BidIncrement
+	// с какого процента от buy_now_price ставками «купить сейчас» пропадает
+	buyNowCutoff int
+	// аукцион не может быть короче
+	minDuration time.Duration
 }
 
 func NewAuctionService(
 	repo AuctionRepo,
 	carRepo CarExistenceRepo,
 	bidRepo BidRepo,
-	orderSvc OrderCreator,
+	orderSvc AuctionOrders,
+	access ShowroomAccess,
+	notifier Notifier,
+	events EventPublisher,
+	softClose model.SoftClose,
+	increments []model.BidIncrement,
+	buyNowCutoff int,
+	minDuration time.Duration,
 ) *AuctionService {
 	return &AuctionService{
 		repo:     repo,
 		carRepo:  carRepo,
 		bidRepo:  bidRepo,
 		orderSvc: orderSvc,
-		finished: make(map[int64]bool),
+		access:   access,
+		notifier: notifier,
+		events:   events,
+		hub:      NewAuctionHub(auctionSubscriberBuffer),
+
+		scheduler: NewAuctionScheduler(),
+
+		softClose:    softClose,
+		increments:   increments,
+		buyNowCutoff: buyNowCutoff,
+		minDuration:  minDuration,
 	}
 }
 
 // ---------- CRUD AUCTIONS ----------
 
-func (s *AuctionService) CreateAuction(a *model.Auction) error {
+func (s *AuctionService) CreateAuction(a *model.Auction, adminID int64) error {
 	// проверяем, что машина существует
-	exists, err := s.carRepo.ExistsByID(a.CarID)
+	car, err := s.carRepo.GetByID(a.CarID)
 	if err != nil {
 		return err
 	}
-	if !exists {
+	if car == nil {
 		return ErrCarNotFound
 	}
+	if err := checkShowroomAccess(s.access, adminID, car.ShowroomID); err != nil {
+		return err
+	}
+
+	// без start_time аукцион начинается сразу
+	if a.StartTime.IsZero() {
+		a.StartTime = time.Now()
+	}
+	if err := s.validateSchedule(a); err != nil {
+		return err
+	}
+	if err := validatePrices(a); err != nil {
+		return err
+	}
 
 	// проверяем, что машина ещё не участвует в другом аукционе
 	used, err := s.repo.ExistsByCarID(a.CarID)
@@ -90,122 +165,695 @@ func (s *AuctionService) CreateAuction(a *model.Auction) error {
 		return ErrCarAlreadyOnAuction
 	}
 
-	return s.repo.Create(a)
+	// на аукцион идёт только машина в продаже, по которой ещё нет заказа
+	if car.DeletedAt != nil || car.Status != model.CarStatusAvailable {
+		return fmt.Errorf("%w: car is %s", ErrCarNotAuctionable, car.Status)
+	}
+	ordered, err := s.orderSvc.IsCarOrdered(car.ID)
+	if err != nil {
+		return err
+	}
+	if ordered {
+		return ErrCarAlreadySold
+	}
+
+	// машина уходит на аукцион: available -> on_auction
+	if err := transitionCarStatus(s.carRepo, car, model.CarStatusOnAuction, nil, "auction created"); err != nil {
+		return err
+	}
+
+	a.Status = openStatus(a.StartTime)
+	if err := s.repo.Create(a); err != nil {
+		_, _ = s.carRepo.TransitionStatus(
+			car.ID, model.CarStatusOnAuction, model.CarStatusAvailable, nil, "auction create failed",
+		)
+		return err
+	}
+	publishCarStatus(s.events, car.ID, model.CarStatusAvailable, model.CarStatusOnAuction, nil)
+
+	a.CurrentPrice = a.StartPrice
+	a.ReserveMet = reserveMet(a)
+	s.setBidState(a)
+	s.schedule(a)
+	return nil
 }
 
 func (s *AuctionService) GetAuctions() ([]model.Auction, error) {
-	return s.repo.GetAll()
+	auctions, err := s.repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	for i := range auctions {
+		s.setBidState(&auctions[i])
+	}
+	return auctions, nil
+}
+
+// GetUpcoming — запланированные аукционы, ближайшие первыми, с отсчётом до начала
+func (s *AuctionService) GetUpcoming() ([]model.Auction, error) {
+	auctions, err := s.repo.GetUpcoming(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	for i := range auctions {
+		s.setBidState(&auctions[i])
+	}
+	return auctions, nil
 }
 
 func (s *AuctionService) GetAuctionByID(id int64) (*model.Auction, error) {
-	return s.repo.GetByID(id)
+	a, err := s.repo.GetByID(id)
+	if err != nil || a == nil {
+		return a, err
+	}
+	s.setBidState(a)
+	return a, nil
 }
 
-func (s *AuctionService) UpdateAuction(a *model.Auction) error {
-	return s.repo.Update(a)
+// UpdateAuction — полная замена (PUT). Машину у аукциона сменить нельзя.
+// expectedVersion == 0 — без проверки версии.
+func (s *AuctionService) UpdateAuction(a *model.Auction, expectedVersion int, adminID int64) (*model.Auction, error) {
+	current, err := s.repo.GetByID(a.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrAuctionNotFound
+	}
+	if a.CarID != 0 && a.CarID != current.CarID {
+		return nil, fmt.Errorf("%w: car_id cannot be changed", ErrInvalidAuction)
+	}
+
+	// полная замена: без reserve_price и buy_now_price они снимаются
+	reserve, buyNow := money.Money(0), money.Money(0)
+	if a.ReservePrice != nil {
+		reserve = *a.ReservePrice
+	}
+	if a.BuyNowPrice != nil {
+		buyNow = *a.BuyNowPrice
+	}
+	// без start_time начало не меняется, иначе оно стало бы нулевой датой
+	if a.StartTime.IsZero() {
+		a.StartTime = current.StartTime
+	}
+
+	return s.PatchAuction(a.ID, model.AuctionPatch{
+		StartPrice:   &a.StartPrice,
+		ReservePrice: &reserve,
+		BuyNowPrice:  &buyNow,
+		StartTime:    &a.StartTime,
+		EndTime:      &a.EndTime,
+	}, expectedVersion, adminID)
 }
 
-func (s *AuctionService) DeleteAuction(id int64) error {
-	return s.repo.Delete(id)
+func (s *AuctionService) PatchAuction(id int64, patch model.AuctionPatch, expectedVersion int, adminID int64) (*model.Auction, error) {
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if a == nil {
+		return nil, ErrAuctionNotFound
+	}
+	if err := s.checkCarAccess(adminID, a.CarID); err != nil {
+		return nil, err
+	}
+	if !model.AuctionOpen(a.Status) {
+		return nil, ErrAuctionFinished
+	}
+	if expectedVersion != 0 && a.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+
+	oldStartPrice, oldEndTime, oldBuyNow := a.StartPrice, a.EndTime, s.buyNowOpen(a)
+
+	// цены, от которых считались уже сделанные ставки, после первой ставки не меняются
+	if patch.StartPrice != nil {
+		if a.BidCount > 0 && *patch.StartPrice != a.StartPrice {
+			return nil, fmt.Errorf("%w: start_price cannot change after bidding has started", ErrInvalidAuction)
+		}
+		a.StartPrice = *patch.StartPrice
+	}
+	if patch.ReservePrice != nil {
+		var current money.Money
+		if a.ReservePrice != nil {
+			current = *a.ReservePrice
+		}
+		if a.BidCount > 0 && *patch.ReservePrice != current {
+			return nil, fmt.Errorf("%w: reserve_price cannot change after bidding has started", ErrInvalidAuction)
+		}
+		a.ReservePrice = patch.ReservePrice
+		if *patch.ReservePrice == 0 {
+			a.ReservePrice = nil
+		}
+	}
+	if patch.BuyNowPrice != nil {
+		a.BuyNowPrice = patch.BuyNowPrice
+		if *patch.BuyNowPrice == 0 {
+			a.BuyNowPrice = nil
+		}
+	}
+	if patch.StartTime != nil {
+		// начавшиеся торги нельзя перенести в будущее задним числом
+		if a.BidCount > 0 && !patch.StartTime.Equal(a.StartTime) {
+			return nil, fmt.Errorf("%w: start_time cannot change after bidding has started", ErrInvalidAuction)
+		}
+		a.StartTime = *patch.StartTime
+	}
+	if patch.EndTime != nil {
+		a.EndTime = *patch.EndTime
+	}
+
+	if err := s.validateSchedule(a); err != nil {
+		return nil, err
+	}
+	if err := validatePrices(a); err != nil {
+		return nil, err
+	}
+	a.Status = openStatus(a.StartTime)
+
+	ok, err := s.repo.Update(a)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrVersionMismatch
+	}
+
+	a.ReserveMet = reserveMet(a)
+
+	// без ставок текущая цена — стартовая
+	if a.BidCount == 0 {
+		a.CurrentPrice = a.StartPrice
+	}
+	s.setBidState(a)
+	if a.StartPrice != oldStartPrice || a.BuyNowAvailable != oldBuyNow {
+		// от стартовой цены зависит минимальная ставка, даже если ставки уже есть
+		s.publish(model.AuctionUpdatePrice, a, "")
+	}
+	if !a.EndTime.Equal(oldEndTime) {
+		s.publish(model.AuctionUpdateEndTime, a, "")
+	}
+	s.schedule(a)
+	return a, nil
+}
+
+func (s *AuctionService) DeleteAuction(id, adminID int64) error {
+	a, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	// уже отменён или закрылся без продажи — удалять нечего
+	if a == nil || a.Status == model.AuctionStatusCancelled || a.Status == model.AuctionStatusNoSale {
+		return nil
+	}
+	if err := s.checkCarAccess(adminID, a.CarID); err != nil {
+		return err
+	}
+
+	// удаление — это отмена: ставки остаются в истории. Рассчитанный или
+	// рассчитываемый аукцион отменить нельзя.
+	ok, err := s.repo.Cancel(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrAuctionFinished
+	}
+	a.Status = model.AuctionStatusCancelled
+	s.scheduler.Remove(id)
+
+	s.publish(model.AuctionUpdateDeleted, a, "")
+	s.hub.CloseAuction(id)
+
+	// аукцион отменён — машина снова в продаже
+	return s.releaseCar(a.CarID, "auction cancelled")
+}
+
+// openStatus — статус идущего аукциона: scheduled до начала, дальше active
+func openStatus(start time.Time) string {
+	if start.After(time.Now()) {
+		return model.AuctionStatusScheduled
+	}
+	return model.AuctionStatusActive
+}
+
+// validateSchedule — порядок времён и минимальная длительность;
+// конец должен быть в будущем, иначе аукцион сразу закроется
+func (s *AuctionService) validateSchedule(a *model.Auction) error {
+	if !a.EndTime.After(a.StartTime) {
+		return fmt.Errorf("%w: end_time must be after start_time", ErrInvalidAuction)
+	}
+	if a.EndTime.Sub(a.StartTime) < s.minDuration {
+		return fmt.Errorf("%w: auction must last at least %s", ErrInvalidAuction, s.minDuration)
+	}
+	if !a.EndTime.After(time.Now()) {
+		return fmt.Errorf("%w: end_time must be in the future", ErrInvalidAuction)
+	}
+	return nil
+}
+
+func validatePrices(a *model.Auction) error {
+	if a.StartPrice < 0 {
+		return fmt.Errorf("%w: start_price must not be negative", ErrInvalidAuction)
+	}
+	if a.ReservePrice != nil && *a.ReservePrice < a.StartPrice {
+		return fmt.Errorf("%w: reserve_price must not be below start_price", ErrInvalidAuction)
+	}
+	if a.BuyNowPrice == nil {
+		return nil
+	}
+	if *a.BuyNowPrice <= a.StartPrice {
+		return fmt.Errorf("%w: buy_now_price must be above start_price", ErrInvalidAuction)
+	}
+	// покупка сразу должна считаться продажей, а не закрытием ниже резерва
+	if a.ReservePrice != nil && *a.BuyNowPrice < *a.ReservePrice {
+		return fmt.Errorf("%w: buy_now_price must not be below reserve_price", ErrInvalidAuction)
+	}
+	return nil
+}
+
+// reserveMet — как reserve_met в AuctionRepository, для уже загруженного аукциона
+func reserveMet(a *model.Auction) *bool {
+	if a.ReservePrice == nil {
+		return nil
+	}
+	met := a.BidCount > 0 && a.CurrentPrice >= *a.ReservePrice
+	return &met
+}
+
+// checkCarAccess — админ управляет салоном, где стоит машина аукциона
+func (s *AuctionService) checkCarAccess(adminID, carID int64) error {
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return err
+	}
+	if car == nil {
+		return ErrCarNotFound
+	}
+	return checkShowroomAccess(s.access, adminID, car.ShowroomID)
+}
+
+// releaseCar возвращает машину с аукциона в продажу, если она всё ещё там.
+func (s *AuctionService) releaseCar(carID int64, reason string) error {
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil || car == nil {
+		return err
+	}
+	if car.Status != model.CarStatusOnAuction {
+		return nil
+	}
+	return transitionCarStatus(s.carRepo, car, model.CarStatusAvailable, nil, reason)
 }
 
 // ---------- BIDS ----------
 
-func (s *AuctionService) PlaceBid(auctionID, userID int64, amount float64) error {
+func (s *AuctionService) PlaceBid(auctionID, userID int64, amount money.Money) error {
 	auction, err := s.repo.GetByID(auctionID)
 	if err != nil {
 		return err
 	}
 	if auction == nil {
-		return errors.New("auction not found")
+		return ErrAuctionNotFound
+	}
+	if amount <= 0 {
+		return fmt.Errorf("%w: bid must be positive", ErrBidTooLow)
 	}
 
-	// нельзя ставить после окончания
-	if time.Now().After(auction.EndTime) {
-		return ErrAuctionFinished
+	bid := &model.Bid{
+		AuctionID: auctionID,
+		UserID:    userID,
+		Amount:    amount,
 	}
 
-	// лимит 3 ставки в минуту
-	count, err := s.bidRepo.UserBidsLimitInMinute(userID, auctionID)
+	// окончание аукциона, лимит ставок и минимальная ставка
+	// max(стартовая цена, текущая цена + шаг) проверяются под блокировкой
+	// аукциона вместе с записью; там же на ставку отвечают ProxyBid
+	res, err := s.bidRepo.Create(bid, s.bidRules())
 	if err != nil {
 		return err
 	}
-	if count >= 3 {
-		return fmt.Errorf("bid limit exceeded: max 3 bids per minute")
+	if err := bidRejection(res); err != nil {
+		return err
 	}
 
-	// проверяем текущую цену
-	maxBid, err := s.bidRepo.GetMaxBidByAuctionID(auctionID)
+	s.publishBids(auction, res.EndTime)
+	return nil
+}
+
+// GetBidHistory — ставки аукциона по времени. Участники видны под
+// псевдонимами; свои ставки зритель видит как свои, админ — всех участников.
+func (s *AuctionService) GetBidHistory(auctionID, viewerID int64, isAdmin bool) ([]model.BidHistoryItem, error) {
+	auction, err := s.repo.GetByID(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if auction == nil {
+		return nil, ErrAuctionNotFound
+	}
+
+	items, err := s.bidRepo.GetHistory(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		it := &items[i]
+		it.Bidder = fmt.Sprintf("Bidder %d", it.BidderNo)
+		it.Mine = it.UserID != nil && *it.UserID == viewerID
+		if !isAdmin {
+			it.UserEmail = nil
+			if !it.Mine {
+				it.UserID = nil
+			}
+		}
+	}
+	return items, nil
+}
+
+// SetProxyBid задаёт или поднимает скрытый максимум пользователя.
+// Если максимум перебивает лидера, ставка за пользователя делается сразу.
+func (s *AuctionService) SetProxyBid(auctionID, userID int64, maxAmount money.Money) (*model.ProxyBid, error) {
+	auction, err := s.repo.GetByID(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if auction == nil {
+		return nil, ErrAuctionNotFound
+	}
+
+	p := &model.ProxyBid{
+		AuctionID: auctionID,
+		UserID:    userID,
+		MaxAmount: maxAmount,
+	}
+	res, err := s.bidRepo.SetProxyBid(p, s.bidRules())
+	if err != nil {
+		return nil, err
+	}
+	if err := bidRejection(res); err != nil {
+		return nil, err
+	}
+
+	s.publishBids(auction, res.EndTime)
+	return p, nil
+}
+
+func (s *AuctionService) bidRules() model.BidRules {
+	return model.BidRules{
+		SoftClose:        s.softClose,
+		Increments:       s.increments,
+		MaxBidsPerMinute: maxBidsPerMinute,
+	}
+}
+
+// bidRejection переводит отказ BidRepo в ошибку сервиса
+func bidRejection(res model.BidResult) error {
+	switch res.Status {
+	case model.BidAccepted:
+		return nil
+	case model.BidAuctionClosed:
+		return ErrAuctionFinished
+	case model.BidNotStarted:
+		return ErrAuctionNotStarted
+	case model.BidTooLow:
+		return fmt.Errorf("%w: minimum bid is %s", ErrBidTooLow, res.MinBid)
+	case model.BidRateLimited:
+		return ErrBidRateLimited
+	case model.BidProxyNotRaised:
+		// понижение позволило бы «отыграть» уже поставленные за пользователя суммы
+		return fmt.Errorf("%w: maximum can only be raised above %s", ErrInvalidProxyBid, res.MinBid)
+	default:
+		return fmt.Errorf("unexpected bid result %q", res.Status)
+	}
+}
+
+// GetProxyBid — максимум виден только его владельцу
+func (s *AuctionService) GetProxyBid(auctionID, userID int64) (*model.ProxyBid, error) {
+	p, err := s.bidRepo.GetProxyBid(auctionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrProxyBidNotFound
+	}
+	return p, nil
+}
+
+// CancelProxyBid — система перестаёт ставить за пользователя;
+// уже сделанные автоматические ставки остаются в силе
+func (s *AuctionService) CancelProxyBid(auctionID, userID int64) error {
+	ok, err := s.bidRepo.DeleteProxyBid(auctionID, userID)
 	if err != nil {
 		return err
 	}
+	if !ok {
+		return ErrProxyBidNotFound
+	}
+	return nil
+}
 
-	currentPrice := 0.0
-	if maxBid != nil {
-		currentPrice = maxBid.Amount
+// BuyNow продаёт машину по buy_now_price: покупка записывается ставкой,
+// аукцион закрывается сразу и финализируется тем же путём, что и по времени,
+// поэтому заказ создаёт OrderService.CreateFromAuction
+func (s *AuctionService) BuyNow(auctionID, userID int64) (*model.Auction, error) {
+	auction, err := s.repo.GetByID(auctionID)
+	if err != nil {
+		return nil, err
+	}
+	if auction == nil {
+		return nil, ErrAuctionNotFound
+	}
+	if !model.AuctionOpen(auction.Status) || time.Now().After(auction.EndTime) {
+		return nil, ErrAuctionFinished
 	}
-	if amount <= currentPrice {
-		return fmt.Errorf("bid must be higher than current price")
+	if time.Now().Before(auction.StartTime) {
+		return nil, ErrAuctionNotStarted
+	}
+	if !s.buyNowOpen(auction) {
+		return nil, ErrBuyNowUnavailable
 	}
 
+	// доступность проверяется ещё раз под блокировкой: параллельная ставка
+	// могла пройти порог или покупка уже состоялась
 	bid := &model.Bid{
 		AuctionID: auctionID,
 		UserID:    userID,
-		Amount:    amount,
 	}
+	ok, err := s.bidRepo.BuyNow(bid, s.buyNowCutoff)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrBuyNowUnavailable
+	}
+
+	s.settle(auctionID)
+
+	a, err := s.repo.GetByID(auctionID)
+	if err != nil || a == nil {
+		return a, err
+	}
+	s.setBidState(a)
+	return a, nil
+}
+
+// publishBids рассылает состояние после записи ставок; before — аукцион
+// до них. Ошибка чтения не отменяет уже принятые ставки.
+func (s *AuctionService) publishBids(before *model.Auction, endTime time.Time) {
+	updated, err := s.repo.GetByID(before.ID)
+	if err != nil || updated == nil {
+		log.Println("error reloading auction after bid:", err)
+		return
+	}
+	if updated.BidCount != before.BidCount {
+		s.publish(model.AuctionUpdateBid, updated, "")
+	}
+	if endTime.After(before.EndTime) {
+		// продление soft close переносит и событие в очереди
+		s.scheduler.Schedule(before.ID, endTime)
+		s.publish(model.AuctionUpdateEndTime, updated, "")
+	}
+}
 
-	return s.bidRepo.Create(bid)
+// setBidState заполняет вычисляемые поля: минимальную ставку и доступность «купить сейчас»
+func (s *AuctionService) setBidState(a *model.Auction) {
+	a.NextMinBid = s.nextMinBid(a)
+	a.BuyNowAvailable = s.buyNowOpen(a)
+	a.StartsIn = 0
+	if a.Status == model.AuctionStatusScheduled {
+		if d := time.Until(a.StartTime); d > 0 {
+			// округляем вверх: пока аукцион не начался, счётчик не показывает 0
+			a.StartsIn = int64((d + time.Second - 1) / time.Second)
+		}
+	}
 }
 
-// ---------- BACKGROUND CHECKER ----------
+func (s *AuctionService) buyNowOpen(a *model.Auction) bool {
+	return model.BuyNowOpen(a.BuyNowPrice, a.CurrentPrice, a.BidCount, s.buyNowCutoff)
+}
 
-func (s *AuctionService) CheckAuctionsEvery5Sec() {
-	auctions, err := s.repo.GetAll()
+// nextMinBid — минимальная допустимая ставка по таблице ступеней
+func (s *AuctionService) nextMinBid(a *model.Auction) money.Money {
+	return model.NextMinBid(s.increments, a.StartPrice, a.CurrentPrice, a.BidCount)
+}
+
+// ---------- REAL-TIME UPDATES ----------
+
+// Subscribe подписывает на обновления аукциона и возвращает его текущее
+// состояние. Подписка оформляется до чтения состояния, чтобы не пропустить
+// ставку между ними.
+func (s *AuctionService) Subscribe(auctionID int64) (*AuctionSubscription, model.AuctionUpdate, error) {
+	sub := s.hub.Subscribe(auctionID)
+
+	a, err := s.repo.GetByID(auctionID)
 	if err != nil {
-		log.Println("error getting auctions:", err)
-		return
+		sub.Close()
+		return nil, model.AuctionUpdate{}, err
+	}
+	if a == nil {
+		sub.Close()
+		return nil, model.AuctionUpdate{}, ErrAuctionNotFound
 	}
+	// статус ended записывается до рассылки итога: если расчёт начался раньше
+	// подписки, узнаём это здесь, иначе подписку закроет settle
+	if !model.AuctionOpen(a.Status) {
+		sub.Close()
+		return nil, model.AuctionUpdate{}, ErrAuctionFinished
+	}
+	return sub, s.auctionUpdate(model.AuctionUpdateSnapshot, a, ""), nil
+}
+
+func (s *AuctionService) publish(kind string, a *model.Auction, result string) {
+	s.hub.Publish(s.auctionUpdate(kind, a, result))
+}
 
+func (s *AuctionService) auctionUpdate(kind string, a *model.Auction, result string) model.AuctionUpdate {
+	return model.AuctionUpdate{
+		Type:            kind,
+		AuctionID:       a.ID,
+		CurrentPrice:    a.CurrentPrice,
+		BidCount:        a.BidCount,
+		ReserveMet:      a.ReserveMet,
+		NextMinBid:      s.nextMinBid(a),
+		BuyNowAvailable: s.buyNowOpen(a) && result == "",
+		EndTime:         a.EndTime,
+		Result:          result,
+		At:              time.Now(),
+	}
+}
+
+// ---------- SCHEDULER ----------
+
+// захват расчёта, который не завершился за это время, считается брошенным
+// (процесс упал) и может быть перехвачен
+const settlementLease = 5 * time.Minute
+
+// RunScheduler загружает очередь из БД и дальше открывает и рассчитывает
+// аукционы точно в срок. Блокируется навсегда.
+func (s *AuctionService) RunScheduler() {
+	s.Reconcile()
+	s.scheduler.Run(s.onAuctionEvent)
+}
+
+// Reconcile сверяет очередь с БД: рассчитывает пропущенные аукционы и
+// брошенные расчёты и ставит в очередь все идущие. Нужна при запуске и
+// изредка потом — чтобы подхватить аукционы, созданные другими инстансами.
+func (s *AuctionService) Reconcile() {
 	now := time.Now()
 
-	for _, a := range auctions {
-		// быстро проверяем, что аукцион уже финализирован
-		s.mu.Lock()
-		if s.finished[a.ID] {
-			s.mu.Unlock()
-			continue
-		}
-		s.mu.Unlock()
+	if _, err := s.repo.ActivateDue(now); err != nil {
+		log.Println("error activating auctions:", err)
+	}
 
-		maxBid, _ := s.bidRepo.GetMaxBidByAuctionID(a.ID)
-		price := a.StartPrice
-		if maxBid != nil && maxBid.Amount > price {
-			price = maxBid.Amount
-		}
+	due, err := s.repo.GetDueForSettlement(now, now.Add(-settlementLease))
+	if err != nil {
+		log.Println("error getting auctions to settle:", err)
+	}
+	for _, a := range due {
+		s.settle(a.ID)
+	}
 
-		// если ещё идёт — просто показываем текущую цену
-		if a.EndTime.After(now) {
-			log.Printf("Auction %d current price: %.2f\n", a.ID, price)
-		} else {
-			// если закончился — финализируем
-			s.finalizeOnce(a)
+	open, err := s.repo.GetOpen()
+	if err != nil {
+		log.Println("error loading open auctions:", err)
+		return
+	}
+	for i := range open {
+		s.schedule(&open[i])
+	}
+}
+
+// schedule ставит в очередь ближайшее событие аукциона: начало или конец
+func (s *AuctionService) schedule(a *model.Auction) {
+	if !model.AuctionOpen(a.Status) {
+		s.scheduler.Remove(a.ID)
+		return
+	}
+	at := a.EndTime
+	if a.Status == model.AuctionStatusScheduled && a.StartTime.Before(at) {
+		at = a.StartTime
+	}
+	s.scheduler.Schedule(a.ID, at)
+}
+
+// onAuctionEvent — наступило начало или конец аукциона. Состояние берётся
+// из БД: за время ожидания аукцион могли изменить, продлить или отменить.
+func (s *AuctionService) onAuctionEvent(auctionID int64) {
+	a, err := s.repo.GetByID(auctionID)
+	if err != nil {
+		log.Println("error loading auction for scheduler:", err)
+		// повторим позже, а не потеряем событие
+		s.scheduler.Schedule(auctionID, time.Now().Add(5*time.Second))
+		return
+	}
+	if a == nil || !model.AuctionOpen(a.Status) {
+		return
+	}
+
+	now := time.Now()
+	switch {
+	case !a.EndTime.After(now):
+		s.settle(auctionID)
+
+	case a.Status == model.AuctionStatusScheduled && !a.StartTime.After(now):
+		if _, err := s.repo.ActivateDue(now); err != nil {
+			log.Println("error activating auctions:", err)
+			s.scheduler.Schedule(auctionID, now.Add(5*time.Second))
+			return
 		}
+		a.Status = model.AuctionStatusActive
+		s.schedule(a)
+
+	default:
+		// событие устарело: время аукциона перенесли
+		s.schedule(a)
 	}
 }
 
-// ---------- FINALIZE AUCTION ONCE ----------
+// ---------- SETTLEMENT ----------
 
-func (s *AuctionService) finalizeOnce(a model.Auction) {
-	// коротко лочим только доступ к map
-	s.mu.Lock()
-	if s.finished[a.ID] {
-		s.mu.Unlock()
+// settle рассчитывает закончившийся аукцион. Расчёт закрепляется в БД
+// условным переходом в ended, поэтому из нескольких процессов (и после
+// перезапуска) аукцион рассчитывает ровно один. Ставки после захвата уже не
+// пройдут — BidRepo сверяет статус и end_time под блокировкой строки.
+func (s *AuctionService) settle(auctionID int64) {
+	now := time.Now()
+	ok, err := s.repo.ClaimSettlement(auctionID, now, now.Add(-settlementLease))
+	if err != nil {
+		log.Println("error claiming auction settlement:", err)
 		return
 	}
-	// помечаем аукцион завершённым СРАЗУ,
-	// чтобы даже при ошибке order он больше не повторялся
-	s.finished[a.ID] = true
-	s.mu.Unlock()
+	if !ok {
+		// продлён ставкой, отменён или уже рассчитывается другим процессом
+		return
+	}
+	s.scheduler.Remove(auctionID)
+
+	current, err := s.repo.GetByID(auctionID)
+	if err != nil || current == nil {
+		log.Println("error reloading auction for settlement:", err)
+		return
+	}
+	a := *current
 
 	maxBid, err := s.bidRepo.GetMaxBidByAuctionID(a.ID)
 	if err != nil {
@@ -213,23 +861,90 @@ func (s *AuctionService) finalizeOnce(a model.Auction) {
 		return
 	}
 
+	result := model.AuctionResultNoBids
 	if maxBid != nil {
+		result = model.AuctionResultSold
+		a.CurrentPrice = maxBid.Amount
+		a.ReserveMet = reserveMet(&a)
+		if a.ReserveMet != nil && !*a.ReserveMet {
+			result = model.AuctionResultReserveNotMet
+		}
+	}
+	status := model.AuctionStatusNoSale
+	if result == model.AuctionResultSold {
 		log.Printf(
-			"Auction %d FINISHED. Winner: user %d, price %.2f\n",
+			"Auction %d FINISHED. Winner: user %d, price %s\n",
 			a.ID,
 			maxBid.UserID,
 			maxBid.Amount,
 		)
 
 		// создаём order из аукциона
-		if err := s.orderSvc.CreateFromAuction(
+		err := s.orderSvc.CreateFromAuction(
 			maxBid.UserID,
 			a.CarID,
 			maxBid.Amount,
-		); err != nil {
+		)
+		switch {
+		case errors.Is(err, ErrCarAlreadySold):
+			// прошлый расчёт мог прерваться после создания заказа —
+			// но заказ должен быть именно победителя и именно с аукциона
+			o, err := s.orderSvc.GetCarOrder(a.CarID)
+			if err != nil {
+				log.Println("error checking auction order:", err)
+				return
+			}
+			if o != nil && o.UserID == maxBid.UserID && o.Source == "auction" {
+				log.Printf("Auction %d: order for car %d already exists\n", a.ID, a.CarID)
+				status = model.AuctionStatusSettled
+			} else {
+				log.Printf("Auction %d: car %d was sold outside the auction, no order for the winner\n", a.ID, a.CarID)
+				result = model.AuctionResultCarSold
+			}
+		case err != nil:
+			// аукцион остаётся в ended, расчёт повторится после settlementLease
 			log.Println("error creating order from auction:", err)
+			return
+		default:
+			status = model.AuctionStatusSettled
 		}
-	} else {
+	}
+
+	finished, err := s.repo.FinishSettlement(a.ID, status, time.Now())
+	if err != nil {
+		// итог не записан — подписчикам ничего не сообщаем, расчёт повторится
+		log.Println("error recording auction settlement:", err)
+		return
+	}
+	if !finished {
+		return
+	}
+
+	// машину возвращаем в продажу и пишем участникам только после записи итога,
+	// иначе повторный расчёт сделал бы это ещё раз
+	switch result {
+	case model.AuctionResultReserveNotMet:
+		log.Printf("Auction %d FINISHED below reserve price, top bid %s\n", a.ID, maxBid.Amount)
+
+		// заказа нет: машина снова в продаже, лидер может предложить свою цену
+		if err := s.releaseCar(a.CarID, "auction ended below reserve price"); err != nil {
+			log.Println("error releasing car after auction:", err)
+		}
+		s.notifier.Notify(
+			maxBid.UserID,
+			"auction_reserve_not_met",
+			fmt.Sprintf("Auction %d ended below the reserve price. You can make an offer for the car via POST /auctions/%d/offer", a.ID, a.ID),
+		)
+
+	case model.AuctionResultNoBids:
 		log.Printf("Auction %d FINISHED with no bids\n", a.ID)
+
+		if err := s.releaseCar(a.CarID, "auction ended with no bids"); err != nil {
+			log.Println("error releasing car after auction:", err)
+		}
 	}
+
+	// подписчики получают итог, после чего потоки аукциона закрываются
+	s.publish(model.AuctionUpdateFinalized, &a, result)
+	s.hub.CloseAuction(a.ID)
 }
@@ -11,6 +11,13 @@ import (
 
 var jwtSecret = []byte("dbc89d984cb0529f2ce6512703389e7f")
 
+// токен потока аукциона передаётся в URL, поэтому живёт недолго
+// и годится только для потока одного аукциона
+const (
+	streamTokenPurpose = "auction_stream"
+	StreamTokenTTL     = time.Minute
+)
+
 type AuthService struct {
 	userRepo UserRepo
 }
@@ -58,3 +65,17 @@ func (s *AuthService) Login(email, password string) (string, error) {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
+
+// IssueStreamToken — токен для ?stream_token= у /auctions/{id}/stream и /ws
+func (s *AuthService) IssueStreamToken(userID int64, role string, auctionID int64) (string, error) {
+	claims := jwt.MapClaims{
+		"user_id":    userID,
+		"role":       role,
+		"purpose":    streamTokenPurpose,
+		"auction_id": auctionID,
+		"exp":        time.Now().Add(StreamTokenTTL).Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret)
+}
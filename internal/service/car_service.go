@@ -1,25 +1,104 @@
 package service
 
-import "car-store/internal/model"
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"car-store/internal/event"
+	"car-store/internal/model"
+	"car-store/internal/vin"
+)
+
+var (
+	ErrInvalidCar      = errors.New("invalid car")
+	ErrVersionMismatch = errors.New("resource was modified by someone else")
+
+	ErrCarArchived        = errors.New("car is archived")
+	ErrCarNotArchived     = errors.New("car is not archived")
+	ErrCarHasOrder        = errors.New("car has an order and cannot be archived")
+	ErrCarOnActiveAuction = errors.New("car is on an active auction and cannot be archived")
+	ErrCarHasTradeIn      = errors.New("car has an accepted trade-in and cannot be archived")
+	ErrCarHasReservation  = errors.New("car is reserved and cannot be archived")
+	ErrSameShowroom       = errors.New("car is already in this showroom")
+)
 
 type CarRepo interface {
 	Create(car *model.Car) error
 	GetAll() ([]model.Car, error)
 	GetByID(id int64) (*model.Car, error)
-	Update(car *model.Car) error
-	Delete(id int64) error
+	Update(car *model.Car, changedBy *int64, toStatus, reason string) (bool, error)
+	GetPriceHistory(carID int64) ([]model.CarPriceChange, error)
+	GetArchived() ([]model.Car, error)
+	Archive(id int64) (bool, error)
+	Restore(id int64) (bool, error)
+	ArchiveBlockers(id int64) (model.CarArchiveBlockers, error)
 	ExistsByID(id int64) (bool, error)
+	TransitionStatus(id int64, from, to string, changedBy *int64, reason string) (bool, error)
+	GetStatusHistory(carID int64) ([]model.CarStatusChange, error)
+	GetFiltered(f model.CarFilter) ([]model.Car, error)
+	TransferShowroom(t *model.CarTransfer, expectedVersion int) (bool, error)
+	GetTransfers(carID int64) ([]model.CarTransfer, error)
+}
+
+// CarShowrooms — салоны и права админов на них (ShowroomService)
+type CarShowrooms interface {
+	ShowroomAccess
+	GetShowroom(id int64) (*model.Showroom, error)
+}
+
+// EventPublisher — шина доменных событий (event.Bus)
+type EventPublisher interface {
+	Publish(e event.Event)
 }
 
 type CarService struct {
-	repo CarRepo
+	repo       CarRepo
+	events     EventPublisher
+	currencies CurrencyChecker
+	showrooms  CarShowrooms
 }
 
-func NewCarService(repo CarRepo) *CarService {
-	return &CarService{repo: repo}
+func NewCarService(repo CarRepo, events EventPublisher, currencies CurrencyChecker, showrooms CarShowrooms) *CarService {
+	return &CarService{repo: repo, events: events, currencies: currencies, showrooms: showrooms}
 }
 
-func (s *CarService) CreateCar(car *model.Car) error {
+// CreateCar — если указан VIN, незаполненные марка, страна и год
+// берутся из него, а расхождения с введёнными данными попадают в car.VINMismatches.
+// Админ с ограничением по салонам может создать машину только в своём салоне.
+func (s *CarService) CreateCar(car *model.Car, adminID int64) error {
+	if car.ShowroomID != nil {
+		if _, err := s.showrooms.GetShowroom(*car.ShowroomID); err != nil {
+			return err
+		}
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return err
+	}
+
+	// новая машина может быть только в продаже или снятой с продажи
+	switch car.Status {
+	case "":
+		car.Status = model.CarStatusAvailable
+	case model.CarStatusAvailable, model.CarStatusWithdrawn:
+	default:
+		return ErrInvalidStatusTransition
+	}
+
+	car.BodyType = strings.ToLower(strings.TrimSpace(car.BodyType))
+	car.VIN = vin.Normalize(car.VIN)
+	if car.VIN != "" {
+		if err := applyVIN(car, true); err != nil {
+			return err
+		}
+	}
+
+	car.Currency = NormalizeCurrency(car.Currency)
+	if err := s.validateCar(car); err != nil {
+		return err
+	}
+
 	return s.repo.Create(car)
 }
 
@@ -27,14 +106,378 @@ func (s *CarService) GetCars() ([]model.Car, error) {
 	return s.repo.GetAll()
 }
 
+// GetCarsInCity — машины салонов города (без архивных)
+func (s *CarService) GetCarsInCity(city string) ([]model.Car, error) {
+	return s.repo.GetFiltered(model.CarFilter{City: strings.TrimSpace(city)})
+}
+
+// GetCarByID возвращает и архивные машины — отсеивает их вызывающий
 func (s *CarService) GetCarByID(id int64) (*model.Car, error) {
 	return s.repo.GetByID(id)
 }
 
-func (s *CarService) UpdateCar(car *model.Car) error {
-	return s.repo.Update(car)
+func (s *CarService) GetArchivedCars() ([]model.Car, error) {
+	return s.repo.GetArchived()
+}
+
+// UpdateCar — полная замена полей машины (PUT).
+// expectedVersion == 0 — без проверки версии.
+// Салон так не меняется — только через TransferCar.
+func (s *CarService) UpdateCar(car *model.Car, expectedVersion int, adminID int64) (*model.Car, error) {
+	patch := model.CarPatch{
+		Brand:         &car.Brand,
+		Model:         &car.Model,
+		Year:          &car.Year,
+		Price:         &car.Price,
+		Currency:      &car.Currency,
+		IsAuctionOnly: &car.IsAuctionOnly,
+		VIN:           &car.VIN,
+		ExternalID:    &car.ExternalID,
+		Country:       &car.Country,
+		BodyType:      &car.BodyType,
+	}
+	if car.Status != "" {
+		patch.Status = &car.Status
+	}
+	return s.PatchCar(car.ID, patch, expectedVersion, adminID)
+}
+
+// PatchCar меняет только переданные поля. Статус меняется только
+// через разрешённый админу переход, в одной транзакции с остальными полями.
+func (s *CarService) PatchCar(id int64, patch model.CarPatch, expectedVersion int, adminID int64) (*model.Car, error) {
+	car, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil {
+		return nil, ErrCarNotFound
+	}
+	if car.DeletedAt != nil {
+		return nil, ErrCarArchived
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return nil, err
+	}
+	if expectedVersion != 0 && car.Version != expectedVersion {
+		return nil, ErrVersionMismatch
+	}
+
+	oldPrice, oldCurrency := car.Price, car.Currency
+
+	if patch.Brand != nil {
+		car.Brand = strings.TrimSpace(*patch.Brand)
+	}
+	if patch.Model != nil {
+		car.Model = strings.TrimSpace(*patch.Model)
+	}
+	if patch.Year != nil {
+		car.Year = *patch.Year
+	}
+	if patch.Price != nil {
+		car.Price = *patch.Price
+	}
+	if patch.Currency != nil {
+		car.Currency = NormalizeCurrency(*patch.Currency)
+	}
+	if patch.IsAuctionOnly != nil {
+		car.IsAuctionOnly = *patch.IsAuctionOnly
+	}
+	if patch.VIN != nil {
+		car.VIN = vin.Normalize(*patch.VIN)
+	}
+	if patch.ExternalID != nil {
+		car.ExternalID = strings.TrimSpace(*patch.ExternalID)
+	}
+	if patch.Country != nil {
+		car.Country = strings.TrimSpace(*patch.Country)
+	}
+	if patch.BodyType != nil {
+		car.BodyType = strings.ToLower(strings.TrimSpace(*patch.BodyType))
+	}
+
+	// при смене VIN только сверяем данные, ничего не подставляем
+	if patch.VIN != nil && car.VIN != "" {
+		if err := applyVIN(car, false); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.validateCar(car); err != nil {
+		return nil, err
+	}
+
+	prevStatus, toStatus := car.Status, ""
+	if patch.Status != nil && *patch.Status != car.Status {
+		if err := checkAdminCarStatus(car.Status, *patch.Status); err != nil {
+			return nil, err
+		}
+		toStatus = *patch.Status
+	}
+
+	ok, err := s.repo.Update(car, &adminID, toStatus, "admin update")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrVersionMismatch
+	}
+
+	// смена одной лишь валюты — тоже изменение цены
+	if car.Price != oldPrice || car.Currency != oldCurrency {
+		s.events.Publish(event.PriceChangedEvent{
+			CarID:       car.ID,
+			OldPrice:    oldPrice,
+			OldCurrency: oldCurrency,
+			NewPrice:    car.Price,
+			NewCurrency: car.Currency,
+			ChangedBy:   &adminID,
+			ChangedAt:   time.Now(),
+		})
+	}
+
+	if car.Status != prevStatus {
+		publishCarStatus(s.events, car.ID, prevStatus, car.Status, &adminID)
+	}
+
+	return car, nil
+}
+
+func (s *CarService) DecodeVIN(v string) (*vin.Info, error) {
+	return vin.Decode(v)
+}
+
+// applyVIN сверяет марку, страну и год с VIN.
+// fill == true — пустые поля заполняются из VIN.
+func applyVIN(c *model.Car, fill bool) error {
+	info, err := vin.Decode(c.VIN)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidCar, err)
+	}
+
+	c.VINMismatches = nil
+
+	switch {
+	case info.Brand == "":
+	case c.Brand == "" && fill:
+		c.Brand = info.Brand
+	case c.Brand != "" && !sameBrand(c.Brand, info.Brand):
+		c.VINMismatches = append(c.VINMismatches,
+			fmt.Sprintf("brand: entered %q, VIN says %q", c.Brand, info.Brand))
+	}
+
+	switch {
+	case info.Country == "":
+	case c.Country == "" && fill:
+		c.Country = info.Country
+	case c.Country != "" && !strings.EqualFold(c.Country, info.Country):
+		c.VINMismatches = append(c.VINMismatches,
+			fmt.Sprintf("country: entered %q, VIN says %q", c.Country, info.Country))
+	}
+
+	switch {
+	case info.ModelYear == 0:
+	case c.Year == 0 && fill:
+		c.Year = info.ModelYear
+	case c.Year != 0 && c.Year != info.ModelYear:
+		c.VINMismatches = append(c.VINMismatches,
+			fmt.Sprintf("year: entered %d, VIN says %d", c.Year, info.ModelYear))
+	}
+
+	if info.CheckDigitRequired && !info.CheckDigitValid {
+		c.VINMismatches = append(c.VINMismatches, "vin: check digit does not match")
+	}
+
+	return nil
+}
+
+// sameBrand сравнивает марки без учёта регистра, пробелов и дефисов:
+// "Mercedes" совпадает с "Mercedes-Benz"
+func sameBrand(entered, decoded string) bool {
+	norm := func(s string) string {
+		s = strings.ToLower(s)
+		s = strings.ReplaceAll(s, "-", "")
+		return strings.ReplaceAll(s, " ", "")
+	}
+	a, b := norm(entered), norm(decoded)
+	return a == b || strings.HasPrefix(b, a) || strings.HasPrefix(a, b)
+}
+
+// validateCar дополняет проверку полей (функция validateCar)
+// проверкой, что для валюты цены есть курс
+func (s *CarService) validateCar(c *model.Car) error {
+	if err := validateCar(c); err != nil {
+		return err
+	}
+	ok, err := s.currencies.IsSupported(c.Currency)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: currency %s has no exchange rate", ErrInvalidCar, c.Currency)
+	}
+	return nil
+}
+
+func validateCar(c *model.Car) error {
+	switch {
+	case c.Brand == "":
+		return fmt.Errorf("%w: brand is required", ErrInvalidCar)
+	case c.Model == "":
+		return fmt.Errorf("%w: model is required", ErrInvalidCar)
+	case c.Year < 1886 || c.Year > time.Now().Year()+1:
+		return fmt.Errorf("%w: year is out of range", ErrInvalidCar)
+	case c.Price < 0:
+		return fmt.Errorf("%w: price must not be negative", ErrInvalidCar)
+	case c.VIN != "" && !vin.IsWellFormed(c.VIN):
+		return fmt.Errorf("%w: vin must be 17 characters without I, O and Q", ErrInvalidCar)
+	}
+	return nil
+}
+
+// ChangeStatus — явный перевод машины в другой статус админом.
+// Брони и аукционы так не ставятся и не снимаются — см. checkAdminCarStatus.
+func (s *CarService) ChangeStatus(carID int64, to string, adminID int64, reason string) (*model.Car, error) {
+	car, err := s.repo.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil {
+		return nil, ErrCarNotFound
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return nil, err
+	}
+
+	if err := checkAdminCarStatus(car.Status, to); err != nil {
+		return nil, err
+	}
+
+	prevStatus := car.Status
+	if err := transitionCarStatus(s.repo, car, to, &adminID, reason); err != nil {
+		return nil, err
+	}
+	publishCarStatus(s.events, car.ID, prevStatus, car.Status, &adminID)
+	return car, nil
+}
+
+func (s *CarService) GetPriceHistory(carID int64) ([]model.CarPriceChange, error) {
+	return s.repo.GetPriceHistory(carID)
+}
+
+func (s *CarService) GetStatusHistory(carID int64) ([]model.CarStatusChange, error) {
+	return s.repo.GetStatusHistory(carID)
+}
+
+// DeleteCar не удаляет строку, а убирает машину в архив.
+// Машину, на которую ссылается заказ, активный аукцион, принятый trade-in
+// или активная бронь, убрать нельзя.
+func (s *CarService) DeleteCar(id, adminID int64) error {
+	car, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if car == nil {
+		return ErrCarNotFound
+	}
+	if car.DeletedAt != nil {
+		return ErrCarArchived
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return err
+	}
+
+	// блокеры проверяются в самом UPDATE, отдельно читаем их только для ответа
+	ok, err := s.repo.Archive(id)
+	if err != nil || ok {
+		return err
+	}
+
+	b, err := s.repo.ArchiveBlockers(id)
+	if err != nil {
+		return err
+	}
+	switch {
+	case b.HasOrder:
+		return ErrCarHasOrder
+	case b.HasActiveAuction:
+		return ErrCarOnActiveAuction
+	case b.HasAcceptedTradeIn:
+		return ErrCarHasTradeIn
+	case b.HasActiveReservation:
+		return ErrCarHasReservation
+	}
+	return ErrCarArchived
+}
+
+func (s *CarService) RestoreCar(id, adminID int64) (*model.Car, error) {
+	car, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil {
+		return nil, ErrCarNotFound
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Restore(id)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		exists, err := s.repo.ExistsByID(id)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrCarNotFound
+		}
+		return nil, ErrCarNotArchived
+	}
+	return s.repo.GetByID(id)
+}
+
+// TransferCar переносит машину в другой салон. Нужны права на салон,
+// где машина сейчас; перемещение пишется в car_transfers.
+func (s *CarService) TransferCar(carID, toShowroomID, adminID int64, reason string) (*model.CarTransfer, error) {
+	car, err := s.repo.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil {
+		return nil, ErrCarNotFound
+	}
+	if car.DeletedAt != nil {
+		return nil, ErrCarArchived
+	}
+	if err := checkShowroomAccess(s.showrooms, adminID, car.ShowroomID); err != nil {
+		return nil, err
+	}
+	if car.ShowroomID != nil && *car.ShowroomID == toShowroomID {
+		return nil, ErrSameShowroom
+	}
+	if _, err := s.showrooms.GetShowroom(toShowroomID); err != nil {
+		return nil, err
+	}
+
+	t := &model.CarTransfer{
+		CarID:          carID,
+		FromShowroomID: car.ShowroomID,
+		ToShowroomID:   toShowroomID,
+		TransferredBy:  adminID,
+		Reason:         strings.TrimSpace(reason),
+	}
+	ok, err := s.repo.TransferShowroom(t, car.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrVersionMismatch
+	}
+	return t, nil
 }
 
-func (s *CarService) DeleteCar(id int64) error {
-	return s.repo.Delete(id)
+func (s *CarService) GetTransfers(carID int64) ([]model.CarTransfer, error) {
+	return s.repo.GetTransfers(carID)
 }
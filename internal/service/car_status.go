@@ -0,0 +1,118 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"car-store/internal/event"
+	"car-store/internal/model"
+)
+
+var (
+	ErrInvalidCarStatus        = errors.New("invalid car status")
+	ErrInvalidStatusTransition = errors.New("car status transition not allowed")
+	ErrCarStatusConflict       = errors.New("car status was changed concurrently")
+)
+
+// разрешённые переходы статусов машины
+var carStatusTransitions = map[string][]string{
+	model.CarStatusAvailable: {
+		model.CarStatusReserved,
+		model.CarStatusOnAuction,
+		model.CarStatusSold,
+		model.CarStatusWithdrawn,
+	},
+	model.CarStatusReserved: {
+		model.CarStatusAvailable,
+		model.CarStatusSold,
+		model.CarStatusWithdrawn,
+	},
+	model.CarStatusOnAuction: {
+		model.CarStatusAvailable,
+		model.CarStatusSold,
+	},
+	model.CarStatusWithdrawn: {
+		model.CarStatusAvailable,
+	},
+	// sold — конечный статус
+	model.CarStatusSold: {},
+}
+
+func IsValidCarStatus(status string) bool {
+	_, ok := carStatusTransitions[status]
+	return ok
+}
+
+func CanTransitionCarStatus(from, to string) bool {
+	for _, s := range carStatusTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// reserved и on_auction ставят и снимают только брони и аукционы:
+// ручная смена разошлась бы со связанной записью брони или аукциона
+func isManagedCarStatus(status string) bool {
+	return status == model.CarStatusReserved || status == model.CarStatusOnAuction
+}
+
+// checkAdminCarStatus — можно ли админу вручную перевести машину из from в to
+func checkAdminCarStatus(from, to string) error {
+	if !IsValidCarStatus(to) {
+		return ErrInvalidCarStatus
+	}
+	for _, s := range []string{from, to} {
+		if isManagedCarStatus(s) {
+			return fmt.Errorf("%w: %s is managed by reservations and auctions", ErrInvalidStatusTransition, s)
+		}
+	}
+	if !CanTransitionCarStatus(from, to) {
+		return ErrInvalidStatusTransition
+	}
+	return nil
+}
+
+type CarStatusRepo interface {
+	GetByID(id int64) (*model.Car, error)
+	TransitionStatus(id int64, from, to string, changedBy *int64, reason string) (bool, error)
+}
+
+// transitionCarStatus проверяет переход и атомарно меняет статус машины.
+// changedBy == nil означает автоматический переход (заказ, аукцион и т.д.).
+func transitionCarStatus(repo CarStatusRepo, car *model.Car, to string, changedBy *int64, reason string) error {
+	// машина в архиве не продаётся и не выставляется на аукцион
+	if car.DeletedAt != nil {
+		return ErrCarArchived
+	}
+	if !IsValidCarStatus(to) {
+		return ErrInvalidCarStatus
+	}
+	if !CanTransitionCarStatus(car.Status, to) {
+		return ErrInvalidStatusTransition
+	}
+
+	ok, err := repo.TransitionStatus(car.ID, car.Status, to, changedBy, reason)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrCarStatusConflict
+	}
+
+	car.Status = to
+	return nil
+}
+
+// publishCarStatus сообщает подписчикам (тест-драйвы и т.д.) о смене статуса машины
+func publishCarStatus(events EventPublisher, carID int64, from, to string, changedBy *int64) {
+	events.Publish(event.CarStatusChangedEvent{
+		CarID:     carID,
+		From:      from,
+		To:        to,
+		ChangedBy: changedBy,
+		ChangedAt: time.Now(),
+	})
+}
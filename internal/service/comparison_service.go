@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+const maxCompareCars = 4
+
+var ErrInvalidComparison = errors.New("compare requires from 2 to 4 distinct car ids")
+
+type CarComparisonRepo interface {
+	GetByIDs(ids []int64) ([]model.Car, error)
+	GetCatalogAveragePrice() (money.Money, error)
+	GetFavoriteCounts(ids []int64) (map[int64]int, error)
+	GetLatestAuctions(ids []int64) (map[int64]model.CarAuctionSummary, error)
+}
+
+type ComparisonService struct {
+	repo   CarComparisonRepo
+	prices PriceConverter
+}
+
+func NewComparisonService(repo CarComparisonRepo, prices PriceConverter) *ComparisonService {
+	return &ComparisonService{repo: repo, prices: prices}
+}
+
+func (s *ComparisonService) CompareCars(ids []int64) (*model.CarComparison, error) {
+	if len(ids) < 2 || len(ids) > maxCompareCars {
+		return nil, ErrInvalidComparison
+	}
+	seen := map[int64]bool{}
+	for _, id := range ids {
+		if seen[id] {
+			return nil, ErrInvalidComparison
+		}
+		seen[id] = true
+	}
+
+	found, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := map[int64]model.Car{}
+	for _, c := range found {
+		byID[c.ID] = c
+	}
+
+	// машины в том порядке, в каком их передали
+	cars := make([]model.Car, 0, len(ids))
+	for _, id := range ids {
+		c, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", ErrCarNotFound, id)
+		}
+		cars = append(cars, c)
+	}
+
+	avg, err := s.repo.GetCatalogAveragePrice()
+	if err != nil {
+		return nil, err
+	}
+	favorites, err := s.repo.GetFavoriteCounts(ids)
+	if err != nil {
+		return nil, err
+	}
+	auctions, err := s.repo.GetLatestAuctions(ids)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	result := &model.CarComparison{
+		Cars:            cars,
+		Matrix:          comparisonMatrix(cars),
+		CatalogAvgPrice: avg,
+	}
+
+	for _, c := range cars {
+		m := model.CarComparisonMetrics{
+			CarID:         c.ID,
+			FavoriteCount: favorites[c.ID],
+		}
+
+		m.AgeYears = now.Year() - c.Year
+		if m.AgeYears < 0 {
+			m.AgeYears = 0
+		}
+		// машина текущего года считается годовалой, чтобы не делить на ноль
+		m.PricePerYearOfAge = c.Price.MulFrac(1, int64(max(m.AgeYears, 1)))
+
+		// средняя по каталогу — в тенге, поэтому и цену машины сравниваем в тенге
+		priceKZT, err := s.prices.ToKZT(c.Price, c.Currency)
+		if err != nil {
+			return nil, err
+		}
+
+		m.PricePosition = "average"
+		if avg > 0 {
+			m.PriceVsAverage = round2((priceKZT - avg).Float64() / avg.Float64() * 100)
+			switch {
+			case m.PriceVsAverage < -5:
+				m.PricePosition = "below_average"
+			case m.PriceVsAverage > 5:
+				m.PricePosition = "above_average"
+			}
+		}
+
+		if a, ok := auctions[c.ID]; ok {
+			m.Auction = &a
+		}
+
+		result.Metrics = append(result.Metrics, m)
+	}
+
+	return result, nil
+}
+
+// comparisonMatrix — характеристики построчно; Differs — значения не все одинаковые
+func comparisonMatrix(cars []model.Car) []model.ComparisonRow {
+	fields := []struct {
+		name  string
+		value func(c model.Car) interface{}
+	}{
+		{"brand", func(c model.Car) interface{} { return c.Brand }},
+		{"model", func(c model.Car) interface{} { return c.Model }},
+		{"year", func(c model.Car) interface{} { return c.Year }},
+		{"price", func(c model.Car) interface{} { return c.Price }},
+		{"currency", func(c model.Car) interface{} { return c.Currency }},
+		{"body_type", func(c model.Car) interface{} { return c.BodyType }},
+		{"country", func(c model.Car) interface{} { return c.Country }},
+		{"status", func(c model.Car) interface{} { return c.Status }},
+		{"is_auction_only", func(c model.Car) interface{} { return c.IsAuctionOnly }},
+	}
+
+	rows := make([]model.ComparisonRow, 0, len(fields))
+	for _, f := range fields {
+		row := model.ComparisonRow{Field: f.name}
+		for _, c := range cars {
+			v := f.value(c)
+			if len(row.Values) > 0 && !reflect.DeepEqual(row.Values[0], v) {
+				row.Differs = true
+			}
+			row.Values = append(row.Values, v)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
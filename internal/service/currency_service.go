@@ -0,0 +1,188 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidExchangeRate = errors.New("exchange rate must be positive")
+	ErrUserNotFound        = errors.New("user not found")
+)
+
+type ExchangeRateRepo interface {
+	GetAll() ([]model.ExchangeRate, error)
+	Get(currency string) (*model.ExchangeRate, error)
+	Upsert(er *model.ExchangeRate) error
+}
+
+type UserCurrencyRepo interface {
+	GetByID(id int64) (*model.User, error)
+	SetPreferredCurrency(userID int64, currency string) (bool, error)
+}
+
+// CurrencyChecker — проверка, что для валюты заведён курс
+type CurrencyChecker interface {
+	IsSupported(currency string) (bool, error)
+}
+
+// PriceConverter — пересчёт сумм по курсам из exchange_rates
+type PriceConverter interface {
+	ToKZT(amount money.Money, currency string) (money.Money, error)
+}
+
+type CurrencyService struct {
+	rates ExchangeRateRepo
+	users UserCurrencyRepo
+}
+
+func NewCurrencyService(rates ExchangeRateRepo, users UserCurrencyRepo) *CurrencyService {
+	return &CurrencyService{rates: rates, users: users}
+}
+
+// NormalizeCurrency приводит код к верхнему регистру; пустой код — валюта по умолчанию
+func NormalizeCurrency(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if code == "" {
+		return model.DefaultCurrency
+	}
+	return code
+}
+
+// isCurrencyCode — три латинские буквы, как в ISO 4217
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *CurrencyService) GetRates() ([]model.ExchangeRate, error) {
+	return s.rates.GetAll()
+}
+
+// SetRate — админ задаёт курс валюты к тенге. Курс самого тенге всегда 1.
+func (s *CurrencyService) SetRate(currency string, kztPerUnit float64, adminID int64) (*model.ExchangeRate, error) {
+	currency = NormalizeCurrency(currency)
+	if !isCurrencyCode(currency) || currency == model.CurrencyKZT {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	if kztPerUnit <= 0 || math.IsInf(kztPerUnit, 0) || math.IsNaN(kztPerUnit) {
+		return nil, ErrInvalidExchangeRate
+	}
+
+	er := &model.ExchangeRate{Currency: currency, KZTPerUnit: kztPerUnit, UpdatedBy: &adminID}
+	if err := s.rates.Upsert(er); err != nil {
+		return nil, err
+	}
+	return er, nil
+}
+
+// KZTPerUnit — сколько тенге стоит единица валюты
+func (s *CurrencyService) KZTPerUnit(currency string) (float64, error) {
+	currency = NormalizeCurrency(currency)
+	er, err := s.rates.Get(currency)
+	if err != nil {
+		return 0, err
+	}
+	if er == nil {
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+	return er.KZTPerUnit, nil
+}
+
+// IsSupported — для валюты заведён курс
+func (s *CurrencyService) IsSupported(currency string) (bool, error) {
+	er, err := s.rates.Get(NormalizeCurrency(currency))
+	return er != nil, err
+}
+
+func (s *CurrencyService) ToKZT(amount money.Money, currency string) (money.Money, error) {
+	rate, err := s.KZTPerUnit(currency)
+	if err != nil {
+		return 0, err
+	}
+	return amount.MulRate(rate), nil
+}
+
+// Convert пересчитывает сумму через тенге и округляет до копеек
+func (s *CurrencyService) Convert(amount money.Money, from, to string) (*model.ConvertedPrice, error) {
+	from, to = NormalizeCurrency(from), NormalizeCurrency(to)
+	if from == to {
+		return &model.ConvertedPrice{Amount: amount, Currency: to, Rate: 1}, nil
+	}
+
+	fromRate, err := s.KZTPerUnit(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := s.KZTPerUnit(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := fromRate / toRate
+	return &model.ConvertedPrice{
+		Amount:   amount.MulRate(rate),
+		Currency: to,
+		Rate:     rate,
+	}, nil
+}
+
+// ApplyDisplayPrice заполняет car.DisplayPrice в валюте target
+func (s *CurrencyService) ApplyDisplayPrice(cars []model.Car, target string) error {
+	if target == "" {
+		return nil
+	}
+	for i := range cars {
+		p, err := s.Convert(cars[i].Price, cars[i].Currency, target)
+		if err != nil {
+			return err
+		}
+		cars[i].DisplayPrice = p
+	}
+	return nil
+}
+
+// PreferredCurrency — валюта из профиля пользователя, пусто если не задана
+func (s *CurrencyService) PreferredCurrency(userID int64) (string, error) {
+	u, err := s.users.GetByID(userID)
+	if err != nil || u == nil {
+		return "", err
+	}
+	return u.PreferredCurrency, nil
+}
+
+// SetPreferredCurrency — пустая строка сбрасывает предпочтение
+func (s *CurrencyService) SetPreferredCurrency(userID int64, currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency != "" {
+		ok, err := s.IsSupported(currency)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+		}
+	}
+
+	ok, err := s.users.SetPreferredCurrency(userID, currency)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"car-store/internal/model"
+	"car-store/internal/storage"
+)
+
+// MaxDocumentSize — предельный размер одного документа, 20 MB
+const MaxDocumentSize = 20 << 20
+
+var (
+	ErrDocumentNotFound     = errors.New("document not found")
+	ErrInvalidDocument      = errors.New("invalid document")
+	ErrDocumentTooLarge     = errors.New("document is larger than 20 MB")
+	ErrDocumentAuthRequired = errors.New("sign in to view this document")
+	ErrDocumentForbidden    = errors.New("document is not available to you")
+)
+
+// разрешённые форматы и их расширения — формат определяется по содержимому файла, а не по имени
+var documentContentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+type DocumentRepo interface {
+	Create(d *model.CarDocument) error
+	GetByID(id int64) (*model.CarDocument, error)
+	GetByCar(carID int64) ([]model.CarDocument, error)
+	Delete(id int64) (bool, error)
+	IsBuyer(userID, carID int64) (bool, error)
+}
+
+type CarGetter interface {
+	GetByID(id int64) (*model.Car, error)
+}
+
+// DocumentViewer — кто запрашивает документ; UserID == 0 — без авторизации
+type DocumentViewer struct {
+	UserID int64
+	Role   string
+}
+
+func (v DocumentViewer) isAdmin() bool { return v.Role == "admin" }
+
+type DocumentService struct {
+	repo    DocumentRepo
+	cars    CarGetter
+	storage storage.Storage
+	access  ShowroomAccess
+}
+
+func NewDocumentService(repo DocumentRepo, cars CarGetter, store storage.Storage, access ShowroomAccess) *DocumentService {
+	return &DocumentService{repo: repo, cars: cars, storage: store, access: access}
+}
+
+// Upload сохраняет файл в хранилище и запись о нём в БД.
+// Если запись не создалась, файл удаляется.
+func (s *DocumentService) Upload(ctx context.Context, doc *model.CarDocument, adminID int64, r io.Reader) error {
+	if err := validateDocument(doc); err != nil {
+		return err
+	}
+
+	car, err := s.cars.GetByID(doc.CarID)
+	if err != nil {
+		return err
+	}
+	if car == nil {
+		return ErrCarNotFound
+	}
+	if car.DeletedAt != nil {
+		return ErrCarArchived
+	}
+	if err := checkShowroomAccess(s.access, adminID, car.ShowroomID); err != nil {
+		return err
+	}
+
+	br := bufio.NewReaderSize(r, 512)
+	head, err := br.Peek(512)
+	if err != nil && err != io.EOF && !errors.Is(err, bufio.ErrBufferFull) {
+		return err
+	}
+	if len(head) == 0 {
+		return fmt.Errorf("%w: file is empty", ErrInvalidDocument)
+	}
+	doc.ContentType = http.DetectContentType(head)
+	if _, ok := documentContentTypes[doc.ContentType]; !ok {
+		return fmt.Errorf("%w: only PDF and images are accepted, got %s", ErrInvalidDocument, doc.ContentType)
+	}
+
+	doc.StorageKey, err = documentKey(doc.CarID, doc.ContentType)
+	if err != nil {
+		return err
+	}
+	doc.UploadedBy = &adminID
+
+	// читаем на байт больше лимита, чтобы отличить "ровно лимит" от "больше"
+	n, err := s.storage.Save(ctx, doc.StorageKey, io.LimitReader(br, MaxDocumentSize+1))
+	if err != nil {
+		return err
+	}
+	if n > MaxDocumentSize {
+		s.removeFile(doc.StorageKey)
+		return ErrDocumentTooLarge
+	}
+	doc.SizeBytes = n
+
+	if err := s.repo.Create(doc); err != nil {
+		s.removeFile(doc.StorageKey)
+		return err
+	}
+	return nil
+}
+
+// ListForCar — документы машины, которые может видеть viewer
+func (s *DocumentService) ListForCar(carID int64, viewer DocumentViewer) ([]model.CarDocument, error) {
+	docs, err := s.repo.GetByCar(carID)
+	if err != nil {
+		return nil, err
+	}
+
+	visible := []model.CarDocument{}
+	for _, d := range docs {
+		ok, err := s.canView(d, viewer)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			visible = append(visible, d)
+		}
+	}
+	return visible, nil
+}
+
+// Open возвращает документ и его содержимое; вызывающий закрывает reader
+func (s *DocumentService) Open(ctx context.Context, id int64, viewer DocumentViewer) (*model.CarDocument, io.ReadCloser, error) {
+	doc, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if doc == nil {
+		return nil, nil, ErrDocumentNotFound
+	}
+
+	ok, err := s.canView(*doc, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		if viewer.UserID == 0 {
+			return nil, nil, ErrDocumentAuthRequired
+		}
+		return nil, nil, ErrDocumentForbidden
+	}
+
+	rc, err := s.storage.Open(ctx, doc.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, ErrDocumentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, rc, nil
+}
+
+func (s *DocumentService) Delete(ctx context.Context, id, adminID int64) error {
+	doc, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if doc == nil {
+		return ErrDocumentNotFound
+	}
+
+	car, err := s.cars.GetByID(doc.CarID)
+	if err != nil {
+		return err
+	}
+	if car != nil {
+		if err := checkShowroomAccess(s.access, adminID, car.ShowroomID); err != nil {
+			return err
+		}
+	}
+
+	ok, err := s.repo.Delete(id)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrDocumentNotFound
+	}
+	// файл удаляем после записи: осиротевший файл лучше битой ссылки
+	return s.storage.Delete(ctx, doc.StorageKey)
+}
+
+func (s *DocumentService) canView(d model.CarDocument, viewer DocumentViewer) (bool, error) {
+	switch {
+	case d.Visibility == model.DocumentPublic:
+		return true, nil
+	case viewer.isAdmin():
+		return true, nil
+	case d.Visibility == model.DocumentBuyersOnly && viewer.UserID != 0:
+		return s.repo.IsBuyer(viewer.UserID, d.CarID)
+	default:
+		return false, nil
+	}
+}
+
+func (s *DocumentService) removeFile(key string) {
+	if err := s.storage.Delete(context.Background(), key); err != nil {
+		log.Println("error removing document file:", err)
+	}
+}
+
+func validateDocument(d *model.CarDocument) error {
+	switch d.Type {
+	case model.DocumentInspection, model.DocumentServiceRecord, model.DocumentRegistration:
+	default:
+		return fmt.Errorf("%w: type must be inspection, service_record or registration", ErrInvalidDocument)
+	}
+
+	if d.Visibility == "" {
+		d.Visibility = model.DocumentAdminOnly
+	}
+	switch d.Visibility {
+	case model.DocumentPublic, model.DocumentBuyersOnly, model.DocumentAdminOnly:
+	default:
+		return fmt.Errorf("%w: visibility must be public, buyers or admin_only", ErrInvalidDocument)
+	}
+
+	d.FileName = filepath.Base(strings.TrimSpace(d.FileName))
+	if d.FileName == "." || d.FileName == string(filepath.Separator) {
+		d.FileName = "document"
+	}
+	return nil
+}
+
+// documentKey — случайный ключ в хранилище; имя файла от клиента в путь не попадает
+func documentKey(carID int64, contentType string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("cars/%d/%s%s", carID, hex.EncodeToString(b), documentContentTypes[contentType]), nil
+}
@@ -2,7 +2,10 @@ package service
 
 import (
 	"errors"
+	"fmt"
+	"log"
 
+	"car-store/internal/event"
 	"car-store/internal/model"
 )
 
@@ -13,14 +16,17 @@ type FavoriteRepo interface {
 	Remove(userID, carID int64) error
 	Exists(userID, carID int64) (bool, error)
 	GetByUser(userID int64) ([]model.Car, error)
+	GetUserIDsByCar(carID int64) ([]int64, error)
 }
 
 type FavoriteService struct {
-	repo FavoriteRepo
+	repo     FavoriteRepo
+	notifier Notifier
+	prices   PriceConverter
 }
 
-func NewFavoriteService(repo FavoriteRepo) *FavoriteService {
-	return &FavoriteService{repo: repo}
+func NewFavoriteService(repo FavoriteRepo, notifier Notifier, prices PriceConverter) *FavoriteService {
+	return &FavoriteService{repo: repo, notifier: notifier, prices: prices}
 }
 
 func (s *FavoriteService) AddToFavorites(userID, carID int64) error {
@@ -41,3 +47,39 @@ func (s *FavoriteService) RemoveFromFavorites(userID, carID int64) error {
 func (s *FavoriteService) GetMyFavorites(userID int64) ([]model.Car, error) {
 	return s.repo.GetByUser(userID)
 }
+
+// OnPriceChanged — подписчик на event.PriceChanged:
+// сообщает о снижении цены всем, у кого машина в избранном.
+// Цены в разных валютах сравниваются в тенге по текущему курсу.
+func (s *FavoriteService) OnPriceChanged(e event.Event) {
+	pc, ok := e.(event.PriceChangedEvent)
+	if !ok {
+		return
+	}
+
+	oldKZT, err := s.prices.ToKZT(pc.OldPrice, pc.OldCurrency)
+	if err != nil {
+		log.Println("error converting old price:", err)
+		return
+	}
+	newKZT, err := s.prices.ToKZT(pc.NewPrice, pc.NewCurrency)
+	if err != nil {
+		log.Println("error converting new price:", err)
+		return
+	}
+	if newKZT >= oldKZT {
+		return
+	}
+
+	userIDs, err := s.repo.GetUserIDsByCar(pc.CarID)
+	if err != nil {
+		log.Println("error getting favorites for price drop:", err)
+		return
+	}
+
+	msg := fmt.Sprintf("Price of car %d dropped from %s %s to %s %s",
+		pc.CarID, pc.OldPrice, pc.OldCurrency, pc.NewPrice, pc.NewCurrency)
+	for _, id := range userIDs {
+		s.notifier.Notify(id, "price_drop", msg)
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"car-store/internal/event"
+	"car-store/internal/model"
+	"car-store/internal/money"
+	"car-store/internal/vin"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format: use csv or json")
+
+// колонки CSV при импорте и экспорте
+var inventoryCSVHeader = []string{
+	"vin", "external_id", "brand", "model", "year", "price", "is_auction_only", "country", "body_type", "status", "currency",
+}
+
+type InventoryRepo interface {
+	GetFiltered(f model.CarFilter) ([]model.Car, error)
+	UpsertMany(cars []model.Car, allOrNothing, dryRun bool) ([]model.CarUpsertResult, bool, error)
+}
+
+type InventoryService struct {
+	repo       InventoryRepo
+	events     EventPublisher
+	currencies CurrencyChecker
+}
+
+func NewInventoryService(repo InventoryRepo, events EventPublisher, currencies CurrencyChecker) *InventoryService {
+	return &InventoryService{repo: repo, events: events, currencies: currencies}
+}
+
+// ---------- IMPORT ----------
+
+func (s *InventoryService) ImportCars(r io.Reader, format string, opts model.ImportOptions) (*model.ImportReport, error) {
+	var rows []model.ImportRow
+	var err error
+
+	switch strings.ToLower(format) {
+	case "csv":
+		rows, err = parseInventoryCSV(r)
+	case "json":
+		rows, err = parseInventoryJSON(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	validateImportRows(rows)
+	if err := s.validateImportCurrencies(rows); err != nil {
+		return nil, err
+	}
+
+	report := &model.ImportReport{
+		DryRun: opts.DryRun,
+		Total:  len(rows),
+		Rows:   make([]model.ImportRowResult, len(rows)),
+	}
+
+	var valid []model.Car
+	var validIdx []int
+	for i, row := range rows {
+		report.Rows[i] = model.ImportRowResult{
+			Line:       row.Line,
+			VIN:        row.Car.VIN,
+			ExternalID: row.Car.ExternalID,
+			Errors:     row.Errors,
+			Warnings:   row.Car.VINMismatches,
+		}
+		if len(row.Errors) > 0 {
+			report.Rows[i].Action = "invalid"
+			report.Invalid++
+			continue
+		}
+		valid = append(valid, row.Car)
+		validIdx = append(validIdx, i)
+	}
+
+	// в режиме "всё или ничего" невалидная строка отменяет весь импорт
+	if opts.AllOrNothing && report.Invalid > 0 {
+		for _, i := range validIdx {
+			report.Rows[i].Action = "skipped"
+		}
+		return report, nil
+	}
+	if len(valid) == 0 {
+		return report, nil
+	}
+
+	results, committed, err := s.repo.UpsertMany(valid, opts.AllOrNothing, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	report.Committed = committed
+
+	for k, res := range results {
+		// события о смене цены — только если импорт действительно записан
+		if committed && res.PriceChanged {
+			s.events.Publish(event.PriceChangedEvent{
+				CarID:       res.CarID,
+				OldPrice:    res.OldPrice,
+				OldCurrency: res.OldCurrency,
+				NewPrice:    valid[k].Price,
+				NewCurrency: valid[k].Currency,
+				ChangedAt:   time.Now(),
+			})
+		}
+
+		row := &report.Rows[validIdx[k]]
+		row.CarID = res.CarID
+		row.Action = res.Action
+		if res.Err != nil {
+			row.Errors = append(row.Errors, res.Err.Error())
+		}
+
+		switch res.Action {
+		case "created":
+			report.Created++
+		case "updated":
+			report.Updated++
+		case "failed":
+			report.Failed++
+		}
+	}
+
+	return report, nil
+}
+
+func parseInventoryCSV(r io.Reader) ([]model.ImportRow, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("invalid csv header: %w", err)
+	}
+
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"brand", "model", "year", "price"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("csv header is missing column %q", required)
+		}
+	}
+
+	var rows []model.ImportRow
+	line := 1
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			rows = append(rows, model.ImportRow{Line: line, Errors: []string{err.Error()}})
+			continue
+		}
+
+		get := func(name string) string {
+			if i, ok := cols[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		row := model.ImportRow{Line: line}
+		row.Car.VIN = strings.ToUpper(get("vin"))
+		row.Car.ExternalID = get("external_id")
+		row.Car.Brand = get("brand")
+		row.Car.Model = get("model")
+		row.Car.Country = get("country")
+		row.Car.BodyType = strings.ToLower(get("body_type"))
+		row.Car.Currency = strings.ToUpper(get("currency"))
+
+		if row.Car.Year, err = strconv.Atoi(get("year")); err != nil {
+			row.Errors = append(row.Errors, "year must be an integer")
+		}
+		if row.Car.Price, err = money.Parse(get("price")); err != nil {
+			row.Errors = append(row.Errors, "price must be a number")
+		}
+		if v := get("is_auction_only"); v != "" {
+			if row.Car.IsAuctionOnly, err = strconv.ParseBool(v); err != nil {
+				row.Errors = append(row.Errors, "is_auction_only must be true or false")
+			}
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+func parseInventoryJSON(r io.Reader) ([]model.ImportRow, error) {
+	var raw []json.RawMessage
+	if err := json.NewDecoder(r).Decode(&raw); err != nil {
+		return nil, fmt.Errorf("invalid json: expected an array of cars: %w", err)
+	}
+
+	rows := make([]model.ImportRow, len(raw))
+	for i, item := range raw {
+		rows[i].Line = i + 1
+		if err := json.Unmarshal(item, &rows[i].Car); err != nil {
+			rows[i].Errors = append(rows[i].Errors, err.Error())
+			continue
+		}
+		rows[i].Car.VIN = strings.ToUpper(strings.TrimSpace(rows[i].Car.VIN))
+		rows[i].Car.ExternalID = strings.TrimSpace(rows[i].Car.ExternalID)
+		rows[i].Car.Currency = strings.ToUpper(strings.TrimSpace(rows[i].Car.Currency))
+		// id и статус из файла игнорируются
+		rows[i].Car.ID = 0
+		rows[i].Car.Status = ""
+	}
+	return rows, nil
+}
+
+func validateImportRows(rows []model.ImportRow) {
+	seenVIN := map[string]int{}
+	seenExt := map[string]int{}
+	maxYear := time.Now().Year() + 1
+
+	for i := range rows {
+		row := &rows[i]
+
+		// как и при CreateCar: пустые марка, страна и год берутся из VIN,
+		// расхождения с ним попадают в предупреждения строки
+		if row.Car.VIN != "" && vin.IsWellFormed(row.Car.VIN) {
+			if err := applyVIN(&row.Car, true); err != nil {
+				row.Errors = append(row.Errors, err.Error())
+			}
+		}
+		c := row.Car
+
+		if c.Brand == "" {
+			row.Errors = append(row.Errors, "brand is required")
+		}
+		if c.Model == "" {
+			row.Errors = append(row.Errors, "model is required")
+		}
+		if c.Year < 1886 || c.Year > maxYear {
+			row.Errors = append(row.Errors, fmt.Sprintf("year must be between 1886 and %d", maxYear))
+		}
+		if c.Price < 0 {
+			row.Errors = append(row.Errors, "price must not be negative")
+		}
+
+		if c.VIN != "" {
+			if !vin.IsWellFormed(c.VIN) {
+				row.Errors = append(row.Errors, "vin must be 17 characters without I, O and Q")
+			}
+			if prev, ok := seenVIN[c.VIN]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate vin, first seen on line %d", prev))
+			} else {
+				seenVIN[c.VIN] = row.Line
+			}
+		}
+		if c.ExternalID != "" {
+			if prev, ok := seenExt[c.ExternalID]; ok {
+				row.Errors = append(row.Errors, fmt.Sprintf("duplicate external_id, first seen on line %d", prev))
+			} else {
+				seenExt[c.ExternalID] = row.Line
+			}
+		}
+	}
+}
+
+// validateImportCurrencies — пустая валюта допустима: у новой машины
+// будет валюта по умолчанию, у существующей останется прежняя
+func (s *InventoryService) validateImportCurrencies(rows []model.ImportRow) error {
+	known := map[string]bool{}
+	for i := range rows {
+		code := rows[i].Car.Currency
+		if code == "" {
+			continue
+		}
+		ok, seen := known[code]
+		if !seen {
+			var err error
+			if ok, err = s.currencies.IsSupported(code); err != nil {
+				return err
+			}
+			known[code] = ok
+		}
+		if !ok {
+			rows[i].Errors = append(rows[i].Errors, fmt.Sprintf("currency %q has no exchange rate", code))
+		}
+	}
+	return nil
+}
+
+// ---------- EXPORT ----------
+
+func (s *InventoryService) ExportCars(w io.Writer, format string, f model.CarFilter) error {
+	cars, err := s.repo.GetFiltered(f)
+	if err != nil {
+		return err
+	}
+
+	switch strings.ToLower(format) {
+	case "json":
+		if cars == nil {
+			cars = []model.Car{}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(cars)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		if err := cw.Write(inventoryCSVHeader); err != nil {
+			return err
+		}
+		for _, c := range cars {
+			if err := cw.Write([]string{
+				c.VIN,
+				c.ExternalID,
+				c.Brand,
+				c.Model,
+				strconv.Itoa(c.Year),
+				c.Price.String(),
+				strconv.FormatBool(c.IsAuctionOnly),
+				c.Country,
+				c.BodyType,
+				c.Status,
+				c.Currency,
+			}); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return ErrUnsupportedFormat
+	}
+}
@@ -0,0 +1,46 @@
+package service
+
+import (
+	"log"
+
+	"car-store/internal/model"
+)
+
+// Notifier — всё, что нужно другим сервисам, чтобы уведомить пользователя.
+type Notifier interface {
+	Notify(userID int64, kind, message string)
+}
+
+type NotificationRepo interface {
+	Create(n *model.Notification) error
+	GetByUser(userID int64) ([]model.Notification, error)
+	MarkRead(userID, id int64) error
+}
+
+type NotificationService struct {
+	repo NotificationRepo
+}
+
+func NewNotificationService(repo NotificationRepo) *NotificationService {
+	return &NotificationService{repo: repo}
+}
+
+// Notify не возвращает ошибку: уведомление не должно ломать основную операцию.
+func (s *NotificationService) Notify(userID int64, kind, message string) {
+	n := &model.Notification{
+		UserID:  userID,
+		Kind:    kind,
+		Message: message,
+	}
+	if err := s.repo.Create(n); err != nil {
+		log.Printf("error notifying user %d (%s): %v\n", userID, kind, err)
+	}
+}
+
+func (s *NotificationService) GetMy(userID int64) ([]model.Notification, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *NotificationService) MarkRead(userID, id int64) error {
+	return s.repo.MarkRead(userID, id)
+}
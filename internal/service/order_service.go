@@ -4,33 +4,35 @@ import (
 	"errors"
 
 	"car-store/internal/model"
+	"car-store/internal/money"
 )
 
 var (
 	ErrCarAlreadySold = errors.New("car already sold")
 	ErrCarNotFoundO   = errors.New("car not found")
+	ErrCarNotForSale  = errors.New("car is not available for direct purchase")
 )
 
 type OrderRepo interface {
-	Create(o *model.Order) error
+	CreateSellingCar(o *model.Order, fromStatus, reason string, reservationID *int64) (bool, error)
 	GetByUser(userID int64) ([]model.Order, error)
 	ExistsByCarID(carID int64) (bool, error)
-}
-
-type CarReadRepo interface {
-	GetByID(id int64) (*model.Car, error)
-	Update(car *model.Car) error
+	GetByCarID(carID int64) (*model.Order, error)
 }
 
 type OrderService struct {
-	orderRepo OrderRepo
-	carRepo   CarReadRepo
+	orderRepo       OrderRepo
+	carRepo         CarStatusRepo
+	reservationRepo ReservationRepo
+	events          EventPublisher
 }
 
-func NewOrderService(orderRepo OrderRepo, carRepo CarReadRepo) *OrderService {
+func NewOrderService(orderRepo OrderRepo, carRepo CarStatusRepo, reservationRepo ReservationRepo, events EventPublisher) *OrderService {
 	return &OrderService{
-		orderRepo: orderRepo,
-		carRepo:   carRepo,
+		orderRepo:       orderRepo,
+		carRepo:         carRepo,
+		reservationRepo: reservationRepo,
+		events:          events,
 	}
 }
 
@@ -52,24 +54,30 @@ func (s *OrderService) BuyDirect(userID, carID int64) error {
 		return ErrCarAlreadySold
 	}
 
-	order := &model.Order{
-		UserID:     userID,
-		CarID:      carID,
-		TotalPrice: car.Price,
-		Source:     "direct",
-	}
-
-	if err := s.orderRepo.Create(order); err != nil {
-		return err
+	switch car.Status {
+	case model.CarStatusAvailable:
+		return s.createOrder(car, userID, car.Price, "direct", nil)
+	case model.CarStatusReserved:
+		// забронированную машину может купить только тот, кто её забронировал
+		res, err := s.reservationRepo.GetActiveByCarID(carID)
+		if err != nil {
+			return err
+		}
+		if res == nil || res.UserID != userID {
+			return ErrCarReserved
+		}
+		// бронь закрывается в одной транзакции с заказом
+		return s.createOrder(car, userID, car.Price, "direct", &res.ID)
+	case model.CarStatusSold:
+		return ErrCarAlreadySold
+	default:
+		return ErrCarNotForSale
 	}
-
-	car.Status = "sold"
-	return s.carRepo.Update(car)
 }
 
 func (s *OrderService) CreateFromAuction(
 	userID, carID int64,
-	price float64,
+	price money.Money,
 ) error {
 
 	sold, err := s.orderRepo.ExistsByCarID(carID)
@@ -80,24 +88,62 @@ func (s *OrderService) CreateFromAuction(
 		return ErrCarAlreadySold
 	}
 
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return err
+	}
+	if car == nil {
+		return ErrCarNotFoundO
+	}
+
+	return s.createOrder(car, userID, price, "auction", nil)
+}
+
+// createOrder одной транзакцией переводит машину в sold (это и есть "замок" на машину)
+// и создаёт заказ. Сумма заказа — в валюте цены машины (ставки аукциона тоже в ней).
+// reservationID — бронь покупателя, которая закрывается вместе с заказом.
+func (s *OrderService) createOrder(car *model.Car, userID int64, price money.Money, source string, reservationID *int64) error {
+	// машина в архиве не продаётся
+	if car.DeletedAt != nil {
+		return ErrCarArchived
+	}
+	if !CanTransitionCarStatus(car.Status, model.CarStatusSold) {
+		if car.Status == model.CarStatusSold {
+			return ErrCarAlreadySold
+		}
+		return ErrInvalidStatusTransition
+	}
+
 	order := &model.Order{
 		UserID:     userID,
-		CarID:      carID,
+		CarID:      car.ID,
 		TotalPrice: price,
-		Source:     "auction",
+		Currency:   car.Currency,
+		Source:     source,
 	}
 
-	if err := s.orderRepo.Create(order); err != nil {
-		return err
-	}
-
-	car, err := s.carRepo.GetByID(carID)
+	prevStatus := car.Status
+	ok, err := s.orderRepo.CreateSellingCar(order, prevStatus, "order: "+source, reservationID)
 	if err != nil {
 		return err
 	}
+	if !ok {
+		return ErrCarStatusConflict
+	}
+
+	car.Status = model.CarStatusSold
+	publishCarStatus(s.events, car.ID, prevStatus, car.Status, nil)
+	return nil
+}
+
+// IsCarOrdered — по машине уже есть заказ
+func (s *OrderService) IsCarOrdered(carID int64) (bool, error) {
+	return s.orderRepo.ExistsByCarID(carID)
+}
 
-	car.Status = "sold"
-	return s.carRepo.Update(car)
+// GetCarOrder — заказ на машину, nil — машина не продана
+func (s *OrderService) GetCarOrder(carID int64) (*model.Order, error) {
+	return s.orderRepo.GetByCarID(carID)
 }
 
 func (s *OrderService) GetMyOrders(userID int64) ([]model.Order, error) {
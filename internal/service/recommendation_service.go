@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"car-store/internal/model"
+)
+
+const (
+	defaultRecommendationLimit = 10
+	maxRecommendationLimit     = 50
+)
+
+// веса признаков в SimilarityScore, в сумме 1
+const (
+	weightBrand = 0.30
+	weightBody  = 0.20
+	weightYear  = 0.20
+	weightPrice = 0.30
+
+	// разница в годах, при которой вклад года обнуляется
+	yearSpan = 10.0
+)
+
+// насколько сильно каждый источник говорит об интересе пользователя
+const (
+	sourceTradeIn  = 1.0 // машина, под которую оформлен trade-in
+	sourceFavorite = 0.9
+	sourceOrder    = 0.8
+)
+
+type RecommendationCarRepo interface {
+	GetByID(id int64) (*model.Car, error)
+	GetByIDs(ids []int64) ([]model.Car, error)
+	GetFiltered(f model.CarFilter) ([]model.Car, error)
+}
+
+type FavoriteLister interface {
+	GetByUser(userID int64) ([]model.Car, error)
+}
+
+type OrderLister interface {
+	GetByUser(userID int64) ([]model.Order, error)
+}
+
+type TradeInLister interface {
+	GetByUserID(ctx context.Context, userID int64) ([]model.TradeIn, error)
+}
+
+type RecommendationService struct {
+	carRepo      RecommendationCarRepo
+	favoriteRepo FavoriteLister
+	orderRepo    OrderLister
+	tradeInRepo  TradeInLister
+	prices       PriceConverter
+}
+
+func NewRecommendationService(
+	carRepo RecommendationCarRepo,
+	favoriteRepo FavoriteLister,
+	orderRepo OrderLister,
+	tradeInRepo TradeInLister,
+	prices PriceConverter,
+) *RecommendationService {
+	return &RecommendationService{
+		carRepo:      carRepo,
+		favoriteRepo: favoriteRepo,
+		orderRepo:    orderRepo,
+		tradeInRepo:  tradeInRepo,
+		prices:       prices,
+	}
+}
+
+// SimilarityScore — детерминированная похожесть candidate на base, от 0 до 1:
+// совпадение марки и кузова, близость года (линейно до 10 лет)
+// и цены (линейно до 100% от цены base). Цены должны быть в одной валюте.
+func SimilarityScore(base, candidate model.Car) float64 {
+	score := 0.0
+
+	if base.Brand != "" && strings.EqualFold(base.Brand, candidate.Brand) {
+		score += weightBrand
+	}
+	if base.BodyType != "" && strings.EqualFold(base.BodyType, candidate.BodyType) {
+		score += weightBody
+	}
+
+	dy := math.Abs(float64(base.Year - candidate.Year))
+	score += weightYear * math.Max(0, 1-dy/yearSpan)
+
+	if base.Price > 0 {
+		dp := math.Abs((base.Price - candidate.Price).Float64()) / base.Price.Float64()
+		score += weightPrice * math.Max(0, 1-dp)
+	}
+
+	return math.Round(score*10000) / 10000
+}
+
+// RankSimilar оценивает кандидатов относительно base и возвращает top-N.
+// Сама base и машины не в продаже пропускаются.
+// При равном счёте раньше идёт машина с меньшим id.
+func RankSimilar(base model.Car, candidates []model.Car, limit int) []model.Recommendation {
+	var recs []model.Recommendation
+	for _, c := range candidates {
+		if c.ID == base.ID || !recommendable(c) {
+			continue
+		}
+		recs = append(recs, model.Recommendation{Car: c, Score: SimilarityScore(base, c)})
+	}
+	return topRecommendations(recs, limit)
+}
+
+// recommendable — предлагать можно только машины в продаже
+func recommendable(c model.Car) bool {
+	return c.Status == model.CarStatusAvailable && c.DeletedAt == nil
+}
+
+func topRecommendations(recs []model.Recommendation, limit int) []model.Recommendation {
+	sort.Slice(recs, func(i, j int) bool {
+		if recs[i].Score != recs[j].Score {
+			return recs[i].Score > recs[j].Score
+		}
+		return recs[i].Car.ID < recs[j].Car.ID
+	})
+	if len(recs) > limit {
+		recs = recs[:limit]
+	}
+	return recs
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return defaultRecommendationLimit
+	}
+	if limit > maxRecommendationLimit {
+		return maxRecommendationLimit
+	}
+	return limit
+}
+
+func (s *RecommendationService) availableCars() ([]model.Car, error) {
+	return s.carRepo.GetFiltered(model.CarFilter{Status: model.CarStatusAvailable})
+}
+
+// inKZT — копия машины с ценой в тенге, чтобы сравнивать цены в разных валютах
+func (s *RecommendationService) inKZT(c model.Car) (model.Car, error) {
+	price, err := s.prices.ToKZT(c.Price, c.Currency)
+	if err != nil {
+		return c, err
+	}
+	c.Price, c.Currency = price, model.CurrencyKZT
+	return c, nil
+}
+
+// rankInKZT считает похожесть по ценам в тенге, но в ответ кладёт машины как есть
+func (s *RecommendationService) rankInKZT(candidates []model.Car, rank func([]model.Car) []model.Recommendation) ([]model.Recommendation, error) {
+	original := make(map[int64]model.Car, len(candidates))
+	normalized := make([]model.Car, 0, len(candidates))
+	for _, c := range candidates {
+		original[c.ID] = c
+		n, err := s.inKZT(c)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, n)
+	}
+
+	recs := rank(normalized)
+	for i := range recs {
+		recs[i].Car = original[recs[i].Car.ID]
+	}
+	return recs, nil
+}
+
+// SimilarCars — машины в продаже, похожие на данную
+func (s *RecommendationService) SimilarCars(carID int64, limit int) ([]model.Recommendation, error) {
+	base, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+	if base == nil || base.DeletedAt != nil {
+		return nil, ErrCarNotFound
+	}
+
+	candidates, err := s.availableCars()
+	if err != nil {
+		return nil, err
+	}
+
+	baseKZT, err := s.inKZT(*base)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.rankInKZT(candidates, func(cars []model.Car) []model.Recommendation {
+		return RankSimilar(baseKZT, cars, normalizeLimit(limit))
+	})
+}
+
+// interest — машина, которой пользователь уже интересовался
+type interest struct {
+	car    model.Car
+	weight float64
+	reason string
+}
+
+// ForUser строит рекомендации по избранному, заказам и trade-in пользователя.
+// Машины, которые уже в избранном, куплены или выбраны в trade-in, не предлагаются.
+func (s *RecommendationService) ForUser(ctx context.Context, userID int64, limit int) ([]model.Recommendation, error) {
+	interests, exclude, err := s.userInterests(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(interests) == 0 {
+		return []model.Recommendation{}, nil
+	}
+
+	candidates, err := s.availableCars()
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range interests {
+		if interests[i].car, err = s.inKZT(interests[i].car); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.rankInKZT(candidates, func(cars []model.Car) []model.Recommendation {
+		return scoreForInterests(interests, cars, exclude, normalizeLimit(limit))
+	})
+}
+
+// scoreForInterests: счёт кандидата — лучший SimilarityScore по интересам,
+// умноженный на вес источника интереса.
+func scoreForInterests(interests []interest, candidates []model.Car, exclude map[int64]bool, limit int) []model.Recommendation {
+	var recs []model.Recommendation
+	for _, c := range candidates {
+		if exclude[c.ID] || !recommendable(c) {
+			continue
+		}
+
+		best := model.Recommendation{Car: c}
+		for _, in := range interests {
+			score := math.Round(SimilarityScore(in.car, c)*in.weight*10000) / 10000
+			if score > best.Score {
+				best.Score = score
+				best.Reason = in.reason
+			}
+		}
+		if best.Score > 0 {
+			recs = append(recs, best)
+		}
+	}
+	return topRecommendations(recs, limit)
+}
+
+func (s *RecommendationService) userInterests(ctx context.Context, userID int64) ([]interest, map[int64]bool, error) {
+	var interests []interest
+	exclude := map[int64]bool{}
+
+	favorites, err := s.favoriteRepo.GetByUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, c := range favorites {
+		exclude[c.ID] = true
+		interests = append(interests, interest{
+			car:    c,
+			weight: sourceFavorite,
+			reason: fmt.Sprintf("similar to %s %s in your favorites", c.Brand, c.Model),
+		})
+	}
+
+	orders, err := s.orderRepo.GetByUser(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var orderedIDs []int64
+	for _, o := range orders {
+		exclude[o.CarID] = true
+		orderedIDs = append(orderedIDs, o.CarID)
+	}
+	if len(orderedIDs) > 0 {
+		ordered, err := s.carRepo.GetByIDs(orderedIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range ordered {
+			interests = append(interests, interest{
+				car:    c,
+				weight: sourceOrder,
+				reason: fmt.Sprintf("similar to %s %s you bought", c.Brand, c.Model),
+			})
+		}
+	}
+
+	tradeIns, err := s.tradeInRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	var desiredIDs []int64
+	for _, t := range tradeIns {
+		if t.DesiredCarID != nil && t.Status != "rejected" {
+			exclude[*t.DesiredCarID] = true
+			desiredIDs = append(desiredIDs, *t.DesiredCarID)
+		}
+	}
+	if len(desiredIDs) > 0 {
+		desired, err := s.carRepo.GetByIDs(desiredIDs)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, c := range desired {
+			interests = append(interests, interest{
+				car:    c,
+				weight: sourceTradeIn,
+				reason: fmt.Sprintf("similar to %s %s from your trade-in request", c.Brand, c.Model),
+			})
+		}
+	}
+
+	return interests, exclude, nil
+}
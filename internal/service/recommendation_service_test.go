@@ -0,0 +1,175 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+func testCar(id int64, brand, body string, year int, price int64) model.Car {
+	return model.Car{
+		ID:       id,
+		Brand:    brand,
+		BodyType: body,
+		Year:     year,
+		Price:    money.FromUnits(price),
+		Status:   model.CarStatusAvailable,
+	}
+}
+
+func TestSimilarityScore(t *testing.T) {
+	base := testCar(1, "Toyota", "sedan", 2020, 10000)
+
+	tests := []struct {
+		name      string
+		base      model.Car
+		candidate model.Car
+		want      float64
+	}{
+		{"identical", base, testCar(2, "Toyota", "sedan", 2020, 10000), 1},
+		{"brand is case-insensitive", base, testCar(2, "TOYOTA", "Sedan", 2020, 10000), 1},
+		{"brand only", base, testCar(2, "Toyota", "suv", 2020, 10000), 0.8},
+		{"body only", base, testCar(2, "Honda", "sedan", 2020, 10000), 0.7},
+		{"year 5 apart", base, testCar(2, "Toyota", "sedan", 2015, 10000), 0.9},
+		{"year 5 apart, newer", base, testCar(2, "Toyota", "sedan", 2025, 10000), 0.9},
+		{"year 10 apart", base, testCar(2, "Toyota", "sedan", 2010, 10000), 0.8},
+		{"year beyond span", base, testCar(2, "Toyota", "sedan", 1990, 10000), 0.8},
+		{"price +50%", base, testCar(2, "Toyota", "sedan", 2020, 15000), 0.85},
+		{"price -25%", base, testCar(2, "Toyota", "sedan", 2020, 7500), 0.925},
+		{"price +100%", base, testCar(2, "Toyota", "sedan", 2020, 20000), 0.7},
+		{"price beyond span", base, testCar(2, "Toyota", "sedan", 2020, 50000), 0.7},
+		{"nothing in common", base, testCar(2, "Honda", "suv", 1990, 50000), 0},
+		{"empty brand and body never match", testCar(1, "", "", 2020, 10000), testCar(2, "", "", 2020, 10000), 0.5},
+		{"zero base price ignores price", testCar(1, "Toyota", "sedan", 2020, 0), testCar(2, "Toyota", "sedan", 2020, 99999), 0.7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SimilarityScore(tt.base, tt.candidate); got != tt.want {
+				t.Errorf("SimilarityScore = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func recIDs(recs []model.Recommendation) []int64 {
+	ids := make([]int64, len(recs))
+	for i, r := range recs {
+		ids[i] = r.Car.ID
+	}
+	return ids
+}
+
+func equalIDs(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestRankSimilar(t *testing.T) {
+	base := testCar(1, "Toyota", "sedan", 2020, 10000)
+
+	sold := testCar(7, "Toyota", "sedan", 2020, 10000)
+	sold.Status = model.CarStatusSold
+	archived := testCar(8, "Toyota", "sedan", 2020, 10000)
+	archived.DeletedAt = &time.Time{}
+
+	candidates := []model.Car{
+		testCar(6, "Honda", "sedan", 2020, 10000), // 0.7
+		base,
+		testCar(5, "Toyota", "suv", 2020, 10000), // 0.8
+		sold,
+		testCar(4, "Toyota", "sedan", 2020, 10000), // 1
+		archived,
+		testCar(3, "Toyota", "suv", 2020, 10000),   // 0.8
+		testCar(2, "Toyota", "sedan", 2020, 10000), // 1
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []int64
+	}{
+		// равный счёт — по возрастанию id
+		{"all", 10, []int64{2, 4, 3, 5, 6}},
+		{"top 3", 3, []int64{2, 4, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := recIDs(RankSimilar(base, candidates, tt.limit))
+			if !equalIDs(got, tt.want) {
+				t.Errorf("RankSimilar ids = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankSimilarIsStableAcrossInputOrder(t *testing.T) {
+	base := testCar(1, "Toyota", "sedan", 2020, 10000)
+	a := []model.Car{
+		testCar(9, "Toyota", "sedan", 2018, 12000),
+		testCar(3, "Toyota", "sedan", 2018, 12000),
+		testCar(5, "Honda", "sedan", 2021, 9000),
+	}
+	b := []model.Car{a[2], a[0], a[1]}
+
+	got1 := recIDs(RankSimilar(base, a, 10))
+	got2 := recIDs(RankSimilar(base, b, 10))
+	if !equalIDs(got1, got2) {
+		t.Errorf("ranking depends on input order: %v vs %v", got1, got2)
+	}
+}
+
+func TestScoreForInterests(t *testing.T) {
+	interests := []interest{
+		{car: testCar(100, "Toyota", "sedan", 2020, 10000), weight: sourceFavorite, reason: "favorite"},
+		{car: testCar(101, "BMW", "suv", 2022, 30000), weight: sourceTradeIn, reason: "tradein"},
+	}
+
+	sold := testCar(5, "BMW", "suv", 2022, 30000)
+	sold.Status = model.CarStatusSold
+
+	candidates := []model.Car{
+		testCar(1, "Toyota", "sedan", 2020, 10000), // 1 * 0.9
+		testCar(2, "BMW", "suv", 2022, 30000),      // 1 * 1.0
+		testCar(3, "Toyota", "sedan", 2020, 10000), // исключена
+		testCar(4, "Lada", "van", 1980, 90000),     // ни на что не похожа
+		sold,
+	}
+	exclude := map[int64]bool{3: true}
+
+	recs := scoreForInterests(interests, candidates, exclude, 10)
+
+	if got, want := recIDs(recs), []int64{2, 1}; !equalIDs(got, want) {
+		t.Fatalf("ids = %v, want %v", got, want)
+	}
+	if recs[0].Score != 1 || recs[0].Reason != "tradein" {
+		t.Errorf("first = %v %q, want 1 \"tradein\"", recs[0].Score, recs[0].Reason)
+	}
+	if recs[1].Score != 0.9 || recs[1].Reason != "favorite" {
+		t.Errorf("second = %v %q, want 0.9 \"favorite\"", recs[1].Score, recs[1].Reason)
+	}
+}
+
+func TestNormalizeLimit(t *testing.T) {
+	tests := []struct{ in, want int }{
+		{0, defaultRecommendationLimit},
+		{-1, defaultRecommendationLimit},
+		{5, 5},
+		{maxRecommendationLimit + 1, maxRecommendationLimit},
+	}
+	for _, tt := range tests {
+		if got := normalizeLimit(tt.in); got != tt.want {
+			t.Errorf("normalizeLimit(%d) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
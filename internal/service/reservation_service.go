@@ -0,0 +1,162 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"car-store/internal/model"
+	"car-store/internal/money"
+)
+
+var (
+	ErrCarReserved         = errors.New("car is reserved by another user")
+	ErrCarNotReservable    = errors.New("car is not available for reservation")
+	ErrDepositRequired     = errors.New("deposit is below the required amount")
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+type ReservationRepo interface {
+	Create(res *model.Reservation) error
+	GetActiveByCarID(carID int64) (*model.Reservation, error)
+	GetByUser(userID int64) ([]model.Reservation, error)
+	GetLapsed(now time.Time) ([]model.Reservation, error)
+	SetStatus(id int64, status string) (bool, error)
+}
+
+type ReservationService struct {
+	repo     ReservationRepo
+	carRepo  CarStatusRepo
+	notifier Notifier
+
+	window  time.Duration
+	deposit money.Money
+}
+
+func NewReservationService(
+	repo ReservationRepo,
+	carRepo CarStatusRepo,
+	notifier Notifier,
+	window time.Duration,
+	deposit money.Money,
+) *ReservationService {
+	return &ReservationService{
+		repo:     repo,
+		carRepo:  carRepo,
+		notifier: notifier,
+		window:   window,
+		deposit:  deposit,
+	}
+}
+
+func (s *ReservationService) Reserve(userID, carID int64, deposit money.Money) (*model.Reservation, error) {
+	if deposit < s.deposit {
+		return nil, ErrDepositRequired
+	}
+
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil {
+		return nil, ErrCarNotFound
+	}
+	if car.Status == model.CarStatusReserved {
+		return nil, ErrCarReserved
+	}
+	if car.Status != model.CarStatusAvailable || car.IsAuctionOnly {
+		return nil, ErrCarNotReservable
+	}
+
+	// available -> reserved; условный UPDATE не даст забронировать дважды
+	if err := transitionCarStatus(s.carRepo, car, model.CarStatusReserved, &userID, "reserved"); err != nil {
+		if errors.Is(err, ErrCarStatusConflict) {
+			return nil, ErrCarReserved
+		}
+		return nil, err
+	}
+
+	res := &model.Reservation{
+		CarID:     carID,
+		UserID:    userID,
+		Deposit:   deposit,
+		Status:    model.ReservationActive,
+		ExpiresAt: time.Now().Add(s.window),
+	}
+	if err := s.repo.Create(res); err != nil {
+		_, _ = s.carRepo.TransitionStatus(
+			carID, model.CarStatusReserved, model.CarStatusAvailable, nil, "reservation failed",
+		)
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (s *ReservationService) Cancel(userID, carID int64) error {
+	res, err := s.repo.GetActiveByCarID(carID)
+	if err != nil {
+		return err
+	}
+	if res == nil || res.UserID != userID {
+		return ErrReservationNotFound
+	}
+
+	ok, err := s.repo.SetStatus(res.ID, model.ReservationCancelled)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrReservationNotFound
+	}
+
+	return s.releaseCar(carID, &userID, "reservation cancelled")
+}
+
+func (s *ReservationService) GetMyReservations(userID int64) ([]model.Reservation, error) {
+	return s.repo.GetByUser(userID)
+}
+
+// ExpireLapsed снимает просроченные брони и уведомляет пользователей.
+// Вызывается фоновым воркером.
+func (s *ReservationService) ExpireLapsed() {
+	lapsed, err := s.repo.GetLapsed(time.Now())
+	if err != nil {
+		log.Println("error getting lapsed reservations:", err)
+		return
+	}
+
+	for _, res := range lapsed {
+		ok, err := s.repo.SetStatus(res.ID, model.ReservationExpired)
+		if err != nil {
+			log.Println("error expiring reservation:", err)
+			continue
+		}
+		if !ok {
+			// бронь уже отменили или выкупили
+			continue
+		}
+
+		if err := s.releaseCar(res.CarID, nil, "reservation expired"); err != nil {
+			log.Println("error releasing reserved car:", err)
+		}
+
+		s.notifier.Notify(
+			res.UserID,
+			"reservation_expired",
+			fmt.Sprintf("Your reservation of car %d has expired", res.CarID),
+		)
+	}
+}
+
+func (s *ReservationService) releaseCar(carID int64, changedBy *int64, reason string) error {
+	car, err := s.carRepo.GetByID(carID)
+	if err != nil || car == nil {
+		return err
+	}
+	if car.Status != model.CarStatusReserved {
+		return nil
+	}
+	return transitionCarStatus(s.carRepo, car, model.CarStatusAvailable, changedBy, reason)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"car-store/internal/model"
+)
+
+var (
+	ErrShowroomNotFound     = errors.New("showroom not found")
+	ErrInvalidShowroom      = errors.New("invalid showroom")
+	ErrShowroomAccessDenied = errors.New("admin has no access to this showroom")
+	ErrUserNotAdmin         = errors.New("user is not an admin")
+)
+
+// формат часов работы салона
+const hoursLayout = "15:04"
+
+type ShowroomRepo interface {
+	Create(s *model.Showroom) error
+	Update(s *model.Showroom) (bool, error)
+	GetByID(id int64) (*model.Showroom, error)
+	GetAll(city string) ([]model.Showroom, error)
+	GetAdminShowroomIDs(userID int64) ([]int64, error)
+	SetAdminShowroomIDs(userID int64, showroomIDs []int64) error
+}
+
+type UserLookup interface {
+	GetByID(id int64) (*model.User, error)
+}
+
+// ShowroomAccess — проверка, может ли админ управлять машинами салона
+type ShowroomAccess interface {
+	CanManage(adminID int64, showroomID *int64) (bool, error)
+}
+
+type ShowroomService struct {
+	repo  ShowroomRepo
+	users UserLookup
+}
+
+func NewShowroomService(repo ShowroomRepo, users UserLookup) *ShowroomService {
+	return &ShowroomService{repo: repo, users: users}
+}
+
+func (s *ShowroomService) GetShowrooms(city string) ([]model.Showroom, error) {
+	return s.repo.GetAll(strings.TrimSpace(city))
+}
+
+func (s *ShowroomService) GetShowroom(id int64) (*model.Showroom, error) {
+	sr, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if sr == nil {
+		return nil, ErrShowroomNotFound
+	}
+	return sr, nil
+}
+
+// CreateShowroom — только админ без ограничения по салонам
+func (s *ShowroomService) CreateShowroom(sr *model.Showroom, adminID int64) error {
+	global, err := s.isGlobalAdmin(adminID)
+	if err != nil {
+		return err
+	}
+	if !global {
+		return ErrShowroomAccessDenied
+	}
+
+	normalizeShowroom(sr)
+	if err := validateShowroom(sr); err != nil {
+		return err
+	}
+	return s.repo.Create(sr)
+}
+
+func (s *ShowroomService) UpdateShowroom(sr *model.Showroom, adminID int64) (*model.Showroom, error) {
+	if err := checkShowroomAccess(s, adminID, &sr.ID); err != nil {
+		return nil, err
+	}
+
+	normalizeShowroom(sr)
+	if err := validateShowroom(sr); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Update(sr)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrShowroomNotFound
+	}
+	return s.repo.GetByID(sr.ID)
+}
+
+// CanManage: админ без записей в admin_showrooms управляет всеми машинами,
+// остальные — только машинами своих салонов (незакреплёнными — нет).
+func (s *ShowroomService) CanManage(adminID int64, showroomID *int64) (bool, error) {
+	ids, err := s.repo.GetAdminShowroomIDs(adminID)
+	if err != nil {
+		return false, err
+	}
+	if len(ids) == 0 {
+		return true, nil
+	}
+	if showroomID == nil {
+		return false, nil
+	}
+	for _, id := range ids {
+		if id == *showroomID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *ShowroomService) isGlobalAdmin(adminID int64) (bool, error) {
+	ids, err := s.repo.GetAdminShowroomIDs(adminID)
+	return len(ids) == 0, err
+}
+
+func (s *ShowroomService) GetAdminScope(userID int64) ([]int64, error) {
+	ids, err := s.repo.GetAdminShowroomIDs(userID)
+	if ids == nil {
+		ids = []int64{}
+	}
+	return ids, err
+}
+
+// SetAdminScope ограничивает админа userID списком салонов; пустой список — все салоны.
+// Менять ограничения может только админ без ограничений.
+func (s *ShowroomService) SetAdminScope(actorID, userID int64, showroomIDs []int64) error {
+	global, err := s.isGlobalAdmin(actorID)
+	if err != nil {
+		return err
+	}
+	if !global {
+		return ErrShowroomAccessDenied
+	}
+
+	u, err := s.users.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	if u == nil {
+		return ErrUserNotFound
+	}
+	if u.Role != "admin" {
+		return ErrUserNotAdmin
+	}
+
+	for _, id := range showroomIDs {
+		sr, err := s.repo.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if sr == nil {
+			return fmt.Errorf("%w: %d", ErrShowroomNotFound, id)
+		}
+	}
+
+	return s.repo.SetAdminShowroomIDs(userID, showroomIDs)
+}
+
+// checkShowroomAccess — общая проверка прав для сервисов, меняющих машины
+func checkShowroomAccess(access ShowroomAccess, adminID int64, showroomID *int64) error {
+	ok, err := access.CanManage(adminID, showroomID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrShowroomAccessDenied
+	}
+	return nil
+}
+
+func normalizeShowroom(sr *model.Showroom) {
+	sr.Name = strings.TrimSpace(sr.Name)
+	sr.City = strings.TrimSpace(sr.City)
+	sr.Address = strings.TrimSpace(sr.Address)
+	if sr.WorkingHours == nil {
+		sr.WorkingHours = []model.ShowroomHours{}
+	}
+}
+
+func validateShowroom(sr *model.Showroom) error {
+	switch {
+	case sr.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidShowroom)
+	case sr.City == "":
+		return fmt.Errorf("%w: city is required", ErrInvalidShowroom)
+	case sr.Address == "":
+		return fmt.Errorf("%w: address is required", ErrInvalidShowroom)
+	case sr.Latitude < -90 || sr.Latitude > 90:
+		return fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidShowroom)
+	case sr.Longitude < -180 || sr.Longitude > 180:
+		return fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidShowroom)
+	}
+
+	seen := map[time.Weekday]bool{}
+	for _, h := range sr.WorkingHours {
+		if h.Weekday < time.Sunday || h.Weekday > time.Saturday {
+			return fmt.Errorf("%w: weekday must be from 0 (Sunday) to 6", ErrInvalidShowroom)
+		}
+		if seen[h.Weekday] {
+			return fmt.Errorf("%w: working hours for %s are set twice", ErrInvalidShowroom, h.Weekday)
+		}
+		seen[h.Weekday] = true
+
+		opens, err1 := time.Parse(hoursLayout, h.Opens)
+		closes, err2 := time.Parse(hoursLayout, h.Closes)
+		if err1 != nil || err2 != nil {
+			return fmt.Errorf("%w: working hours must be in HH:MM format", ErrInvalidShowroom)
+		}
+		if !closes.After(opens) {
+			return fmt.Errorf("%w: %s closes before it opens", ErrInvalidShowroom, h.Weekday)
+		}
+	}
+	return nil
+}
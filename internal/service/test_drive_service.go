@@ -0,0 +1,488 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"car-store/internal/event"
+	"car-store/internal/model"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// календарь админа — не больше двух месяцев за запрос
+	maxCalendarDays     = 62
+	defaultCalendarDays = 7
+)
+
+var (
+	ErrTestDriveNotFound  = errors.New("test drive not found")
+	ErrInvalidTestDrive   = errors.New("invalid test drive slot")
+	ErrTestDriveSlotTaken = errors.New("this slot is already booked for the car")
+	ErrTestDriveOverlap   = errors.New("you already have a test drive at this time")
+	ErrCarNotTestDrivable = errors.New("car is not available for test drives")
+	ErrTestDriveClosed    = errors.New("test drive is already cancelled or rejected")
+)
+
+type TestDriveRepo interface {
+	Create(td *model.TestDrive) (bool, error)
+	Conflicts(carID, userID int64, startsAt, endsAt time.Time, excludeID int64) (bool, bool, error)
+	GetByID(id int64) (*model.TestDrive, error)
+	GetByUser(userID int64) ([]model.TestDrive, error)
+	GetBookedByCar(carID int64, from, to time.Time) ([]model.TestDrive, error)
+	GetCalendar(from, to time.Time, showroomIDs []int64) ([]model.TestDrive, error)
+	Reschedule(td *model.TestDrive) (bool, error)
+	SetStatus(id int64, status, reason string) (bool, error)
+	RejectForUnavailableCars(now time.Time) ([]model.TestDrive, error)
+}
+
+type ActiveReservationLookup interface {
+	GetActiveByCarID(carID int64) (*model.Reservation, error)
+}
+
+type TestDriveShowrooms interface {
+	ShowroomAccess
+	GetShowroom(id int64) (*model.Showroom, error)
+	GetAdminScope(userID int64) ([]int64, error)
+}
+
+type TestDriveService struct {
+	repo         TestDriveRepo
+	cars         CarGetter
+	reservations ActiveReservationLookup
+	showrooms    TestDriveShowrooms
+	notifier     Notifier
+	slot         time.Duration
+	horizon      time.Duration
+	loc          *time.Location
+}
+
+func NewTestDriveService(
+	repo TestDriveRepo,
+	cars CarGetter,
+	reservations ActiveReservationLookup,
+	showrooms TestDriveShowrooms,
+	notifier Notifier,
+	slot, horizon time.Duration,
+	loc *time.Location,
+) *TestDriveService {
+	return &TestDriveService{
+		repo:         repo,
+		cars:         cars,
+		reservations: reservations,
+		showrooms:    showrooms,
+		notifier:     notifier,
+		slot:         slot,
+		horizon:      horizon,
+		loc:          loc,
+	}
+}
+
+// Slots — слоты на дату (YYYY-MM-DD по местному времени) в салоне, где стоит машина.
+// Занятые, прошедшие и слишком далёкие слоты помечены как недоступные.
+func (s *TestDriveService) Slots(carID int64, date string) ([]model.TestDriveSlot, error) {
+	car, err := s.testDriveCar(carID)
+	if err != nil {
+		return nil, err
+	}
+	sr, err := s.showrooms.GetShowroom(*car.ShowroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := time.ParseInLocation(dateLayout, date, s.loc)
+	if err != nil {
+		return nil, fmt.Errorf("%w: date must be in YYYY-MM-DD format", ErrInvalidTestDrive)
+	}
+
+	slots := s.daySlots(sr, day)
+	if len(slots) == 0 {
+		return slots, nil
+	}
+
+	booked, err := s.repo.GetBookedByCar(carID, slots[0].StartsAt.UTC(), slots[len(slots)-1].EndsAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range slots {
+		slots[i].Available = slots[i].StartsAt.After(now) && !slots[i].StartsAt.After(now.Add(s.horizon))
+		for _, td := range booked {
+			if td.StartsAt.Before(slots[i].EndsAt) && td.EndsAt.After(slots[i].StartsAt) {
+				slots[i].Available = false
+				break
+			}
+		}
+	}
+	return slots, nil
+}
+
+// Book записывает пользователя на тест-драйв. Машина и пользователь
+// заняты не более чем одной записью в каждый момент времени.
+func (s *TestDriveService) Book(userID, carID int64, startsAt time.Time) (*model.TestDrive, error) {
+	car, err := s.testDriveCar(carID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReservedFor(car, userID); err != nil {
+		return nil, err
+	}
+	sr, err := s.showrooms.GetShowroom(*car.ShowroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	endsAt, err := s.validateSlot(sr, startsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	td := &model.TestDrive{
+		CarID:      carID,
+		UserID:     userID,
+		ShowroomID: sr.ID,
+		StartsAt:   startsAt.UTC(),
+		EndsAt:     endsAt.UTC(),
+	}
+	if err := s.checkConflicts(td); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Create(td)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// слот заняли между проверкой и вставкой
+		return nil, ErrTestDriveSlotTaken
+	}
+
+	s.localize(td)
+	return td, nil
+}
+
+// Reschedule переносит свою активную запись на другой слот той же машины
+func (s *TestDriveService) Reschedule(id, userID int64, startsAt time.Time) (*model.TestDrive, error) {
+	td, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if td == nil || td.UserID != userID {
+		return nil, ErrTestDriveNotFound
+	}
+	if td.Status != model.TestDriveBooked {
+		return nil, ErrTestDriveClosed
+	}
+
+	car, err := s.testDriveCar(td.CarID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkReservedFor(car, userID); err != nil {
+		return nil, err
+	}
+	// машину могли перевезти в другой салон — слот считается по текущему
+	sr, err := s.showrooms.GetShowroom(*car.ShowroomID)
+	if err != nil {
+		return nil, err
+	}
+
+	endsAt, err := s.validateSlot(sr, startsAt)
+	if err != nil {
+		return nil, err
+	}
+
+	td.ShowroomID = sr.ID
+	td.StartsAt = startsAt.UTC()
+	td.EndsAt = endsAt.UTC()
+	if err := s.checkConflicts(td); err != nil {
+		return nil, err
+	}
+
+	ok, err := s.repo.Reschedule(td)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrTestDriveSlotTaken
+	}
+
+	return s.getLocal(id)
+}
+
+// Cancel — отменить может сам пользователь или админ салона.
+// Если отменяет админ, пользователь получает уведомление.
+func (s *TestDriveService) Cancel(id, userID int64, isAdmin bool) error {
+	td, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if td == nil {
+		return ErrTestDriveNotFound
+	}
+
+	byOwner := td.UserID == userID
+	if !byOwner {
+		if !isAdmin {
+			return ErrTestDriveNotFound
+		}
+		if err := checkShowroomAccess(s.showrooms, userID, &td.ShowroomID); err != nil {
+			return err
+		}
+	}
+
+	reason := "cancelled by user"
+	if !byOwner {
+		reason = "cancelled by showroom"
+	}
+
+	ok, err := s.repo.SetStatus(id, model.TestDriveCancelled, reason)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrTestDriveClosed
+	}
+
+	if !byOwner {
+		s.notifier.Notify(
+			td.UserID,
+			"test_drive_cancelled",
+			fmt.Sprintf("Your test drive of car %d on %s was cancelled by the showroom",
+				td.CarID, td.StartsAt.In(s.loc).Format("2006-01-02 15:04")),
+		)
+	}
+	return nil
+}
+
+func (s *TestDriveService) GetMy(userID int64) ([]model.TestDrive, error) {
+	list, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		s.localize(&list[i])
+	}
+	return list, nil
+}
+
+// Calendar — записи по дням за [from, to] включительно (даты YYYY-MM-DD).
+// Без showroomID админ видит все свои салоны.
+func (s *TestDriveService) Calendar(adminID int64, from, to string, showroomID *int64) ([]model.TestDriveCalendarDay, error) {
+	start, end, err := s.calendarRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int64
+	if showroomID != nil {
+		if err := checkShowroomAccess(s.showrooms, adminID, showroomID); err != nil {
+			return nil, err
+		}
+		ids = []int64{*showroomID}
+	} else {
+		// пустой список — админ без ограничений, видит все салоны
+		if ids, err = s.showrooms.GetAdminScope(adminID); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := s.repo.GetCalendar(start.UTC(), end.UTC(), ids)
+	if err != nil {
+		return nil, err
+	}
+
+	// каждый день периода — отдельная запись, даже если она пустая
+	var days []model.TestDriveCalendarDay
+	index := map[string]int{}
+	for d := start; d.Before(end); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dateLayout)
+		index[key] = len(days)
+		days = append(days, model.TestDriveCalendarDay{Date: key, TestDrives: []model.TestDrive{}})
+	}
+	for _, td := range list {
+		s.localize(&td)
+		if i, ok := index[td.StartsAt.Format(dateLayout)]; ok {
+			days[i].TestDrives = append(days[i].TestDrives, td)
+		}
+	}
+	return days, nil
+}
+
+// OnCarStatusChanged — подписчик на event.CarStatusChanged: записи на машину,
+// которую продали или выставили на аукцион, отклоняются сразу, не дожидаясь воркера.
+func (s *TestDriveService) OnCarStatusChanged(e event.Event) {
+	sc, ok := e.(event.CarStatusChangedEvent)
+	if !ok {
+		return
+	}
+	if sc.To == model.CarStatusSold || sc.To == model.CarStatusOnAuction {
+		s.RejectUnavailable()
+	}
+}
+
+// RejectUnavailable отклоняет записи на проданные и выставленные на аукцион
+// машины и уведомляет пользователей. Вызывается при смене статуса машины
+// и фоновым воркером — на случай пропущенного события.
+func (s *TestDriveService) RejectUnavailable() {
+	rejected, err := s.repo.RejectForUnavailableCars(time.Now())
+	if err != nil {
+		log.Println("error rejecting test drives:", err)
+		return
+	}
+
+	for _, td := range rejected {
+		s.notifier.Notify(
+			td.UserID,
+			"test_drive_rejected",
+			fmt.Sprintf("Your test drive of car %d on %s was cancelled: %s",
+				td.CarID, td.StartsAt.In(s.loc).Format("2006-01-02 15:04"), td.Reason),
+		)
+	}
+}
+
+// testDriveCar — машина в продаже и закреплена за салоном
+func (s *TestDriveService) testDriveCar(carID int64) (*model.Car, error) {
+	car, err := s.cars.GetByID(carID)
+	if err != nil {
+		return nil, err
+	}
+	if car == nil || car.DeletedAt != nil {
+		return nil, ErrCarNotFound
+	}
+	if car.ShowroomID == nil {
+		return nil, fmt.Errorf("%w: car is not assigned to a showroom", ErrCarNotTestDrivable)
+	}
+	if car.Status != model.CarStatusAvailable && car.Status != model.CarStatusReserved {
+		return nil, fmt.Errorf("%w: car is %s", ErrCarNotTestDrivable, car.Status)
+	}
+	return car, nil
+}
+
+// checkReservedFor — забронированную машину может смотреть только тот, кто её забронировал
+func (s *TestDriveService) checkReservedFor(car *model.Car, userID int64) error {
+	if car.Status != model.CarStatusReserved {
+		return nil
+	}
+	res, err := s.reservations.GetActiveByCarID(car.ID)
+	if err != nil {
+		return err
+	}
+	if res == nil || res.UserID != userID {
+		return fmt.Errorf("%w: car is reserved by another customer", ErrCarNotTestDrivable)
+	}
+	return nil
+}
+
+// daySlots нарезает часы работы салона в этот день на слоты фиксированной длины
+func (s *TestDriveService) daySlots(sr *model.Showroom, day time.Time) []model.TestDriveSlot {
+	slots := []model.TestDriveSlot{}
+	if s.slot <= 0 {
+		return slots
+	}
+
+	for _, h := range sr.WorkingHours {
+		if h.Weekday != day.Weekday() {
+			continue
+		}
+		opens, err1 := time.Parse(hoursLayout, h.Opens)
+		closes, err2 := time.Parse(hoursLayout, h.Closes)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+
+		y, m, d := day.Date()
+		start := time.Date(y, m, d, opens.Hour(), opens.Minute(), 0, 0, s.loc)
+		end := time.Date(y, m, d, closes.Hour(), closes.Minute(), 0, 0, s.loc)
+		for t := start; !t.Add(s.slot).After(end); t = t.Add(s.slot) {
+			slots = append(slots, model.TestDriveSlot{StartsAt: t, EndsAt: t.Add(s.slot)})
+		}
+	}
+	return slots
+}
+
+// validateSlot проверяет, что startsAt — начало слота в часы работы салона,
+// в будущем и не дальше горизонта записи. Возвращает конец слота.
+func (s *TestDriveService) validateSlot(sr *model.Showroom, startsAt time.Time) (time.Time, error) {
+	now := time.Now()
+	if !startsAt.After(now) {
+		return time.Time{}, fmt.Errorf("%w: slot is in the past", ErrInvalidTestDrive)
+	}
+	if startsAt.After(now.Add(s.horizon)) {
+		return time.Time{}, fmt.Errorf("%w: booking is open %s ahead", ErrInvalidTestDrive, s.horizon)
+	}
+
+	for _, slot := range s.daySlots(sr, startsAt.In(s.loc)) {
+		if slot.StartsAt.Equal(startsAt) {
+			return slot.EndsAt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%w: time must be the start of a slot within the showroom working hours", ErrInvalidTestDrive)
+}
+
+func (s *TestDriveService) checkConflicts(td *model.TestDrive) error {
+	carBusy, userBusy, err := s.repo.Conflicts(td.CarID, td.UserID, td.StartsAt, td.EndsAt, td.ID)
+	if err != nil {
+		return err
+	}
+	if carBusy {
+		return ErrTestDriveSlotTaken
+	}
+	if userBusy {
+		return ErrTestDriveOverlap
+	}
+	return nil
+}
+
+func (s *TestDriveService) calendarRange(from, to string) (time.Time, time.Time, error) {
+	var start time.Time
+	if from == "" {
+		y, m, d := time.Now().In(s.loc).Date()
+		start = time.Date(y, m, d, 0, 0, 0, 0, s.loc)
+	} else {
+		var err error
+		if start, err = time.ParseInLocation(dateLayout, from, s.loc); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: from must be in YYYY-MM-DD format", ErrInvalidTestDrive)
+		}
+	}
+
+	// to включительно — конец периода на следующий день в полночь
+	end := start.AddDate(0, 0, defaultCalendarDays)
+	if to != "" {
+		last, err := time.ParseInLocation(dateLayout, to, s.loc)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: to must be in YYYY-MM-DD format", ErrInvalidTestDrive)
+		}
+		end = last.AddDate(0, 0, 1)
+	}
+
+	if !end.After(start) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: to must not be before from", ErrInvalidTestDrive)
+	}
+	if end.After(start.AddDate(0, 0, maxCalendarDays)) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: calendar period is limited to %d days", ErrInvalidTestDrive, maxCalendarDays)
+	}
+	return start, end, nil
+}
+
+// localize — время записи в часовом поясе салонов, как в слотах
+func (s *TestDriveService) localize(td *model.TestDrive) {
+	td.StartsAt = td.StartsAt.In(s.loc)
+	td.EndsAt = td.EndsAt.In(s.loc)
+}
+
+func (s *TestDriveService) getLocal(id int64) (*model.TestDrive, error) {
+	td, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if td == nil {
+		return nil, ErrTestDriveNotFound
+	}
+	s.localize(td)
+	return td, nil
+}
@@ -20,12 +20,18 @@ type TradeInService interface {
 	DeleteTradeIn(ctx context.Context, id int64, userID int64, isAdmin bool) error
 }
 
+// ExchangeRateProvider — курс валюты к тенге
+type ExchangeRateProvider interface {
+	KZTPerUnit(currency string) (float64, error)
+}
+
 type tradeInService struct {
-	repo repository.TradeInRepository
+	repo  repository.TradeInRepository
+	rates ExchangeRateProvider
 }
 
-func NewTradeInService(repo repository.TradeInRepository) TradeInService {
-	return &tradeInService{repo: repo}
+func NewTradeInService(repo repository.TradeInRepository, rates ExchangeRateProvider) TradeInService {
+	return &tradeInService{repo: repo, rates: rates}
 }
 
 func (s *tradeInService) CreateTradeIn(ctx context.Context, userID int64, req model.CreateTradeInRequest) (*model.TradeIn, error) {
@@ -111,8 +117,14 @@ func (s *tradeInService) SetUserPayment(ctx context.Context, id int64, userID in
 		return "", fmt.Errorf("trade-in has no estimated price")
 	}
 
+	// Оценка и доплата — в долларах, kolesa.kz ищет в тенге
+	kztPerUSD, err := s.rates.KZTPerUnit(model.CurrencyUSD)
+	if err != nil {
+		return "", err
+	}
+
 	// Генерируем URL для kolesa.kz
-	kolesaURL := utility.GenerateKolesaURLFromPayment(*tradeIn.EstimatedPrice, req.UserPayment)
+	kolesaURL := utility.GenerateKolesaURLFromPayment(*tradeIn.EstimatedPrice, req.UserPayment, kztPerUSD)
 
 	// Сохраняем user_payment и обновляем статус
 	if err := s.repo.SetUserPayment(ctx, id, req.UserPayment, kolesaURL); err != nil {
@@ -0,0 +1,24 @@
+// Package storage — хранилище файлов (документы машин и т.п.).
+// Сервисы работают только с интерфейсом Storage, поэтому локальный
+// диск можно заменить на S3 или другое хранилище без изменений в них.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var (
+	ErrNotFound   = errors.New("file not found")
+	ErrInvalidKey = errors.New("invalid storage key")
+)
+
+type Storage interface {
+	// Save записывает содержимое r под ключом key и возвращает размер в байтах
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open открывает файл на чтение; вызывающий закрывает его сам
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete удаляет файл; отсутствие файла ошибкой не считается
+	Delete(ctx context.Context, key string) error
+}
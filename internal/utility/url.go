@@ -3,22 +3,25 @@ package utility
 import (
 	"fmt"
 	_ "net/url"
+
+	"car-store/internal/money"
 )
 
-func GenerateKolesaURLFromPayment(estimatedPrice, userPayment float64) string {
+// GenerateKolesaURLFromPayment — суммы в долларах, kztPerUSD — курс из exchange_rates
+func GenerateKolesaURLFromPayment(estimatedPrice, userPayment money.Money, kztPerUSD float64) string {
 	// Вычисляем итоговую сумму
 	totalBudget := estimatedPrice + userPayment
 
 	// Создаем диапазон ±15% для поиска
-	minPrice := int(totalBudget * 0.85)
-	maxPrice := int(totalBudget * 1.15)
+	minPrice := totalBudget.MulFrac(85, 100)
+	maxPrice := totalBudget.MulFrac(115, 100)
 
-	// Конвертируем в тенге (1 USD ≈ 450 KZT)
-	minPriceKZT := minPrice * 450
-	maxPriceKZT := maxPrice * 450
+	// Конвертируем в тенге по сохранённому курсу
+	minPriceKZT := minPrice.MulRate(kztPerUSD)
+	maxPriceKZT := maxPrice.MulRate(kztPerUSD)
 
-	// Формируем финальный URL
-	finalURL := fmt.Sprintf("https://kolesa.kz/cars/?price[from]=%d&price[to]=%d", minPriceKZT, maxPriceKZT)
+	// Формируем финальный URL (kolesa.kz ищет по целым тенге)
+	finalURL := fmt.Sprintf("https://kolesa.kz/cars/?price[from]=%d&price[to]=%d", minPriceKZT.Units(), maxPriceKZT.Units())
 
 	return finalURL
 }
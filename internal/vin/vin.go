@@ -0,0 +1,199 @@
+// Package vin декодирует VIN (ISO 3779) без обращения к сети:
+// производитель и страна — по встроенным таблицам WMI,
+// модельный год — по 10-му символу.
+package vin
+
+import (
+	"embed"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"time"
+)
+
+//go:embed data/*.csv
+var dataFS embed.FS
+
+var ErrInvalidVIN = errors.New("vin must be 17 characters: digits and letters except I, O, Q")
+
+// порядок символов VIN в диапазонах WMI: A..Z (без I, O, Q), затем 1..9, 0
+const vinAlphabet = "ABCDEFGHJKLMNPRSTUVWXYZ1234567890"
+
+// символы 10-й позиции, цикл 30 лет начиная с 1980 (A) / 2010 (A)
+const yearCodes = "ABCDEFGHJKLMNPRSTVWXY123456789"
+
+type Info struct {
+	VIN          string `json:"vin"`
+	WMI          string `json:"wmi"`
+	Brand        string `json:"brand,omitempty"`
+	Country      string `json:"country,omitempty"`
+	ModelYear    int    `json:"model_year,omitempty"`
+	SerialNumber string `json:"serial_number"`
+
+	// контрольная цифра (9-я позиция) обязательна для Северной Америки и Китая
+	CheckDigitRequired bool `json:"check_digit_required"`
+	CheckDigitValid    bool `json:"check_digit_valid"`
+}
+
+type wmiEntry struct {
+	brand   string
+	country string
+}
+
+type countryRange struct {
+	from, to string
+	country  string
+}
+
+var (
+	wmiTable  map[string]wmiEntry
+	countries []countryRange
+)
+
+func init() {
+	wmiTable = map[string]wmiEntry{}
+	for _, rec := range mustReadCSV("data/wmi.csv") {
+		wmiTable[rec[0]] = wmiEntry{brand: rec[1], country: rec[2]}
+	}
+	for _, rec := range mustReadCSV("data/countries.csv") {
+		countries = append(countries, countryRange{from: rec[0], to: rec[1], country: rec[2]})
+	}
+}
+
+func mustReadCSV(name string) [][]string {
+	f, err := dataFS.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+
+	records, err := csv.NewReader(f).ReadAll()
+	if err != nil {
+		panic(err)
+	}
+	return records[1:] // без заголовка
+}
+
+// Normalize приводит VIN к верхнему регистру и убирает пробелы
+func Normalize(v string) string {
+	return strings.ToUpper(strings.TrimSpace(v))
+}
+
+// IsWellFormed — 17 символов, только цифры и латинские буквы кроме I, O, Q
+func IsWellFormed(v string) bool {
+	if len(v) != 17 {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		if strings.IndexByte(vinAlphabet, v[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Decode разбирает VIN. Неизвестный WMI не ошибка — просто пустые Brand/Country.
+func Decode(v string) (*Info, error) {
+	v = Normalize(v)
+	if !IsWellFormed(v) {
+		return nil, ErrInvalidVIN
+	}
+
+	info := &Info{
+		VIN:          v,
+		WMI:          v[:3],
+		SerialNumber: v[11:],
+	}
+
+	if e, ok := wmiTable[info.WMI]; ok {
+		info.Brand = e.brand
+		info.Country = e.country
+	} else {
+		info.Country = countryOf(v[:2])
+	}
+
+	switch v[0] {
+	case '1', '2', '3', '4', '5', 'L':
+		info.CheckDigitRequired = true
+	}
+	info.CheckDigitValid = v[8] == checkDigit(v)
+
+	info.ModelYear = modelYear(v, time.Now().Year()+1)
+
+	return info, nil
+}
+
+// countryOf ищет страну по диапазонам первых двух символов.
+// Диапазон из одного символа ("4".."5") задаёт только первый символ.
+func countryOf(prefix string) string {
+	for _, c := range countries {
+		if len(c.from) == 1 {
+			if inRange(prefix[0], c.from[0], c.to[0]) {
+				return c.country
+			}
+			continue
+		}
+		if prefix[0] == c.from[0] && inRange(prefix[1], c.from[1], c.to[1]) {
+			return c.country
+		}
+	}
+	return ""
+}
+
+func inRange(ch, from, to byte) bool {
+	p := strings.IndexByte(vinAlphabet, ch)
+	return p >= strings.IndexByte(vinAlphabet, from) && p <= strings.IndexByte(vinAlphabet, to)
+}
+
+// modelYear: один и тот же код повторяется каждые 30 лет.
+// Для Северной Америки 7-я позиция подсказывает цикл: цифра — 1980–2009,
+// буква — 2010–2039. Иначе берём самый поздний год не позже maxYear.
+func modelYear(v string, maxYear int) int {
+	idx := strings.IndexByte(yearCodes, v[9])
+	if idx < 0 {
+		return 0
+	}
+
+	first, second := 1980+idx, 2010+idx
+
+	switch v[0] {
+	case '1', '2', '3', '4', '5':
+		if v[6] >= '0' && v[6] <= '9' {
+			return first
+		}
+		return second
+	}
+
+	if second <= maxYear {
+		return second
+	}
+	return first
+}
+
+var transliteration = map[byte]int{
+	'A': 1, 'B': 2, 'C': 3, 'D': 4, 'E': 5, 'F': 6, 'G': 7, 'H': 8,
+	'J': 1, 'K': 2, 'L': 3, 'M': 4, 'N': 5, 'P': 7, 'R': 9,
+	'S': 2, 'T': 3, 'U': 4, 'V': 5, 'W': 6, 'X': 7, 'Y': 8, 'Z': 9,
+}
+
+var weights = [17]int{8, 7, 6, 5, 4, 3, 2, 10, 0, 9, 8, 7, 6, 5, 4, 3, 2}
+
+func checkDigit(v string) byte {
+	sum := 0
+	for i := 0; i < 17; i++ {
+		c := v[i]
+		val := 0
+		if c >= '0' && c <= '9' {
+			val = int(c - '0')
+		} else {
+			val = transliteration[c]
+		}
+		sum += val * weights[i]
+	}
+
+	r := sum % 11
+	if r == 10 {
+		return 'X'
+	}
+	return byte('0' + r)
+}
@@ -0,0 +1,122 @@
+package vin
+
+import "testing"
+
+func TestModelYear(t *testing.T) {
+	tests := []struct {
+		name    string
+		vin     string
+		maxYear int
+		want    int
+	}{
+		// Северная Америка: 7-я позиция выбирает цикл независимо от maxYear
+		{"NA digit at 7th, K", "1M8GDM9AXKP042788", 2027, 1989},
+		{"NA digit at 7th, 3", "1HGCM82633A004352", 2027, 2003},
+		{"NA letter at 7th, K", "1M8GDMAAXKP042788", 2027, 2019},
+		{"NA letter at 7th, 9", "5YJSA1EA19F000001", 2027, 2039},
+		{"NA digit at 7th, A", "4T1BF11K0AU000001", 2027, 1980},
+
+		// остальные регионы: самый поздний год не позже maxYear
+		{"EU A", "WVWZZZ1JZAW000001", 2027, 2010},
+		{"EU L", "WVWZZZ1JZLW000001", 2027, 2020},
+		{"EU Y", "WVWZZZ1JZYW000001", 2027, 2000},
+		{"EU 9", "WVWZZZ1JZ9W000001", 2027, 2009},
+		{"EU boundary equal", "WVWZZZ1JZVW000001", 2027, 2027},
+		{"EU boundary next", "WVWZZZ1JZWW000001", 2027, 1998},
+		{"JP 1", "JTDKB20U113000001", 2027, 2001},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modelYear(tt.vin, tt.maxYear); got != tt.want {
+				t.Errorf("modelYear(%q, %d) = %d, want %d", tt.vin, tt.maxYear, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		vin   string
+		valid bool
+	}{
+		{"1M8GDM9AXKP042788", true},
+		{"1HGCM82633A004352", true},
+		{"11111111111111111", true},
+		{"1HGCM82643A004352", false},
+		{"1M8GDM9A1KP042788", false},
+	}
+
+	for _, tt := range tests {
+		if got := checkDigit(tt.vin) == tt.vin[8]; got != tt.valid {
+			t.Errorf("check digit of %q valid = %v, want %v", tt.vin, got, tt.valid)
+		}
+	}
+}
+
+func TestCountryOf(t *testing.T) {
+	tests := []struct {
+		prefix string
+		want   string
+	}{
+		{"1H", "United States"},
+		{"2T", "Canada"},
+		{"5Y", "United States"},
+		{"JT", "Japan"},
+		{"KM", "South Korea"},
+		{"SN", "Germany"},
+		{"SA", "United Kingdom"},
+		{"VF", "France"},
+		{"WV", "Germany"},
+		{"X5", "Russia"},
+		{"XT", "Russia"},
+		{"Y7", "Ukraine"},
+		{"ZF", "Italy"},
+		{"9B", "Brazil"},
+		{"ZZ", ""},
+		{"AZ", ""},
+	}
+
+	for _, tt := range tests {
+		if got := countryOf(tt.prefix); got != tt.want {
+			t.Errorf("countryOf(%q) = %q, want %q", tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	info, err := Decode(" 1hgcm82633a004352 ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.VIN != "1HGCM82633A004352" || info.WMI != "1HG" || info.SerialNumber != "004352" {
+		t.Errorf("unexpected parts: %+v", info)
+	}
+	if info.Brand != "Honda" || info.Country != "United States" {
+		t.Errorf("brand/country = %q/%q, want Honda/United States", info.Brand, info.Country)
+	}
+	if info.ModelYear != 2003 {
+		t.Errorf("model year = %d, want 2003", info.ModelYear)
+	}
+	if !info.CheckDigitRequired || !info.CheckDigitValid {
+		t.Errorf("check digit required/valid = %v/%v, want true/true", info.CheckDigitRequired, info.CheckDigitValid)
+	}
+
+	// неизвестный WMI — страна по диапазону, марки нет
+	info, err = Decode("VF9ZZZ1JZAW000001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Brand != "" || info.Country != "France" {
+		t.Errorf("brand/country = %q/%q, want \"\"/France", info.Brand, info.Country)
+	}
+	if info.CheckDigitRequired {
+		t.Error("check digit should not be required outside North America and China")
+	}
+
+	for _, bad := range []string{"", "1HGCM82633A00435", "1HGCM82633A0043521", "1HGCM82633A00435O", "1HGCM8263IA004352"} {
+		if _, err := Decode(bad); err != ErrInvalidVIN {
+			t.Errorf("Decode(%q) err = %v, want ErrInvalidVIN", bad, err)
+		}
+	}
+}
@@ -0,0 +1,358 @@
+// Package websocket — минимальная серверная реализация WebSocket (RFC 6455)
+// на стандартной библиотеке: рукопожатие, текстовые сообщения, ping/pong и close.
+// Расширения (permessage-deflate) и подпротоколы не поддерживаются.
+package websocket
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GUID из RFC 6455 для вычисления Sec-WebSocket-Accept
+const acceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	opContinuation = 0x0
+	OpText         = 0x1
+	OpBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// коды закрытия соединения
+const (
+	CloseNormal          = 1000
+	CloseGoingAway       = 1001
+	CloseProtocolError   = 1002
+	ClosePolicyViolation = 1008
+	CloseMessageTooBig   = 1009
+	CloseTryAgainLater   = 1013
+)
+
+const (
+	// входящие сообщения серверу не нужны — большие считаются ошибкой
+	maxMessageSize = 64 << 10
+	// максимальная длина данных управляющего кадра по RFC
+	maxControlPayload = 125
+
+	writeTimeout = 10 * time.Second
+)
+
+var (
+	ErrBadHandshake = errors.New("websocket: bad handshake")
+	ErrBadOrigin    = errors.New("websocket: origin not allowed")
+	ErrClosed       = errors.New("websocket: connection closed by peer")
+	errProtocol     = errors.New("websocket: protocol error")
+	errTooBig       = errors.New("websocket: message too big")
+)
+
+type Conn struct {
+	conn net.Conn
+	br   *bufio.Reader
+
+	wmu      sync.Mutex // кадры пишут и читатель (pong, close), и писатель
+	closed   bool
+	onPong   func()
+	fragment []byte
+}
+
+// Upgrader — настройки рукопожатия
+type Upgrader struct {
+	// с каких страниц браузеру можно открывать соединение, например
+	// "https://shop.example.com". Страница с того же хоста разрешена всегда,
+	// клиент без Origin (не браузер) — тоже.
+	AllowedOrigins []string
+}
+
+// checkOrigin защищает от cross-site WebSocket hijacking: браузер
+// шлёт куки и заголовки на любой хост, но Origin подделать не может
+func (u *Upgrader) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	for _, allowed := range u.AllowedOrigins {
+		if strings.EqualFold(origin, strings.TrimRight(allowed, "/")) {
+			return true
+		}
+	}
+	o, err := url.Parse(origin)
+	return err == nil && strings.EqualFold(o.Host, r.Host)
+}
+
+// Upgrade выполняет рукопожатие и забирает соединение у net/http.
+// При ошибке ответ клиенту уже записан.
+func (u *Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, ErrBadHandshake
+	}
+	if !headerHasToken(r.Header, "Connection", "upgrade") || !headerHasToken(r.Header, "Upgrade", "websocket") {
+		http.Error(w, "websocket upgrade required", http.StatusUpgradeRequired)
+		return nil, ErrBadHandshake
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "unsupported websocket version", http.StatusUpgradeRequired)
+		return nil, ErrBadHandshake
+	}
+	if !u.checkOrigin(r) {
+		http.Error(w, "origin not allowed", http.StatusForbidden)
+		return nil, ErrBadOrigin
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if raw, err := base64.StdEncoding.DecodeString(key); err != nil || len(raw) != 16 {
+		http.Error(w, "invalid Sec-WebSocket-Key", http.StatusBadRequest)
+		return nil, ErrBadHandshake
+	}
+
+	conn, brw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, "websocket is not supported by this connection", http.StatusInternalServerError)
+		return nil, err
+	}
+	// клиент не должен слать кадры до ответа на рукопожатие
+	if brw.Reader.Buffered() > 0 {
+		conn.Close()
+		return nil, ErrBadHandshake
+	}
+
+	// дедлайны net/http после hijack не действуют — сбрасываем их
+	_ = conn.SetDeadline(time.Time{})
+
+	resp := "HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + acceptKey(key) + "\r\n\r\n"
+
+	_ = conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := conn.Write([]byte(resp)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return &Conn{conn: conn, br: brw.Reader}, nil
+}
+
+func acceptKey(key string) string {
+	h := sha1.Sum([]byte(key + acceptGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SetPongHandler задаёт функцию, которую ReadMessage вызывает на каждый pong.
+// Вызывать до запуска чтения.
+func (c *Conn) SetPongHandler(f func()) {
+	c.onPong = f
+}
+
+func (c *Conn) SetReadDeadline(t time.Time) error {
+	return c.conn.SetReadDeadline(t)
+}
+
+// WriteText отправляет текстовое сообщение одним кадром
+func (c *Conn) WriteText(data []byte) error {
+	return c.writeFrame(OpText, data)
+}
+
+func (c *Conn) Ping() error {
+	return c.writeFrame(opPing, nil)
+}
+
+// Close отправляет кадр закрытия с кодом и причиной и закрывает соединение.
+// Повторный вызов ничего не делает.
+func (c *Conn) Close(code int, reason string) error {
+	if len(reason) > maxControlPayload-2 {
+		reason = reason[:maxControlPayload-2]
+	}
+	payload := make([]byte, 2+len(reason))
+	binary.BigEndian.PutUint16(payload, uint16(code))
+	copy(payload[2:], reason)
+
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+
+	_ = c.writeFrameLocked(opClose, payload)
+	return c.conn.Close()
+}
+
+func (c *Conn) writeFrame(op byte, payload []byte) error {
+	c.wmu.Lock()
+	defer c.wmu.Unlock()
+	if c.closed {
+		return net.ErrClosed
+	}
+	return c.writeFrameLocked(op, payload)
+}
+
+// writeFrameLocked пишет один финальный кадр; сервер кадры не маскирует
+func (c *Conn) writeFrameLocked(op byte, payload []byte) error {
+	header := make([]byte, 2, 10)
+	header[0] = 0x80 | op
+
+	n := len(payload)
+	switch {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = binary.BigEndian.AppendUint16(header, uint16(n))
+	default:
+		header[1] = 127
+		header = binary.BigEndian.AppendUint64(header, uint64(n))
+	}
+
+	// медленный клиент не должен держать писателя дольше writeTimeout
+	if err := c.conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+		return err
+	}
+	if _, err := c.conn.Write(append(header, payload...)); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ReadMessage возвращает следующее сообщение с данными. Ping, pong и close
+// обрабатываются здесь же: на ping отправляется pong, на close — ответный
+// close и ErrClosed. При нарушении протокола соединение закрывается.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	var msgOp int
+	for {
+		fin, op, payload, err := c.readFrame()
+		if err != nil {
+			switch {
+			case errors.Is(err, errTooBig):
+				c.Close(CloseMessageTooBig, "message too big")
+			case errors.Is(err, errProtocol):
+				c.Close(CloseProtocolError, "protocol error")
+			}
+			return 0, nil, err
+		}
+
+		switch op {
+		case opPing:
+			if err := c.writeFrame(opPong, payload); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case opPong:
+			if c.onPong != nil {
+				c.onPong()
+			}
+			continue
+		case opClose:
+			code := CloseNormal
+			if len(payload) >= 2 {
+				code = int(binary.BigEndian.Uint16(payload))
+			}
+			c.Close(code, "")
+			return 0, nil, ErrClosed
+		case OpText, OpBinary:
+			if c.fragment != nil {
+				c.Close(CloseProtocolError, "expected continuation frame")
+				return 0, nil, errProtocol
+			}
+			msgOp = int(op)
+			c.fragment = []byte{}
+		case opContinuation:
+			if c.fragment == nil {
+				c.Close(CloseProtocolError, "unexpected continuation frame")
+				return 0, nil, errProtocol
+			}
+		default:
+			c.Close(CloseProtocolError, "unknown opcode")
+			return 0, nil, errProtocol
+		}
+
+		if len(c.fragment)+len(payload) > maxMessageSize {
+			c.Close(CloseMessageTooBig, "message too big")
+			return 0, nil, errTooBig
+		}
+		c.fragment = append(c.fragment, payload...)
+
+		if fin {
+			msg := c.fragment
+			c.fragment = nil
+			return msgOp, msg, nil
+		}
+	}
+}
+
+func (c *Conn) readFrame() (fin bool, op byte, payload []byte, err error) {
+	var head [2]byte
+	if _, err = io.ReadFull(c.br, head[:]); err != nil {
+		return
+	}
+	fin = head[0]&0x80 != 0
+	op = head[0] & 0x0F
+	if head[0]&0x70 != 0 {
+		// RSV-биты без согласованных расширений запрещены
+		return fin, op, nil, fmt.Errorf("%w: reserved bits set", errProtocol)
+	}
+	// клиент обязан маскировать кадры
+	if head[1]&0x80 == 0 {
+		return fin, op, nil, fmt.Errorf("%w: unmasked client frame", errProtocol)
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err = io.ReadFull(c.br, ext[:]); err != nil {
+			return
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if op >= opClose && (!fin || length > maxControlPayload) {
+		return fin, op, nil, fmt.Errorf("%w: invalid control frame", errProtocol)
+	}
+	if length > maxMessageSize {
+		return fin, op, nil, errTooBig
+	}
+
+	var mask [4]byte
+	if _, err = io.ReadFull(c.br, mask[:]); err != nil {
+		return
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.br, payload); err != nil {
+		return
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return fin, op, payload, nil
+}
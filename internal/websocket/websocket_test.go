@@ -0,0 +1,428 @@
+package websocket
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testKey = "dGhlIHNhbXBsZSBub25jZQ=="
+
+func TestAcceptKey(t *testing.T) {
+	// пример из RFC 6455, раздел 1.3
+	if got := acceptKey(testKey); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("acceptKey = %q", got)
+	}
+}
+
+// testServer поднимает сервер, который делает Upgrade и передаёт соединение в serve
+func testServer(t *testing.T, u *Upgrader, serve func(c *Conn)) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := u.Upgrade(w, r)
+		if err != nil {
+			return
+		}
+		serve(c)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+type testClient struct {
+	t    *testing.T
+	conn net.Conn
+	br   *bufio.Reader
+}
+
+// handshake отправляет запрос на upgrade и возвращает код ответа
+func handshake(t *testing.T, srv *httptest.Server, origin string) (*testClient, int) {
+	t.Helper()
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	req := "GET / HTTP/1.1\r\n" +
+		"Host: " + srv.Listener.Addr().String() + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: keep-alive, Upgrade\r\n" +
+		"Sec-WebSocket-Version: 13\r\n" +
+		"Sec-WebSocket-Key: " + testKey + "\r\n"
+	if origin != "" {
+		req += "Origin: " + origin + "\r\n"
+	}
+	if _, err := conn.Write([]byte(req + "\r\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode == http.StatusSwitchingProtocols {
+		if got := resp.Header.Get("Sec-WebSocket-Accept"); got != acceptKey(testKey) {
+			t.Errorf("Sec-WebSocket-Accept = %q", got)
+		}
+	}
+	return &testClient{t: t, conn: conn, br: br}, resp.StatusCode
+}
+
+func dial(t *testing.T, serve func(c *Conn)) *testClient {
+	t.Helper()
+	c, code := handshake(t, testServer(t, &Upgrader{}, serve), "")
+	if code != http.StatusSwitchingProtocols {
+		t.Fatalf("handshake status = %d", code)
+	}
+	return c
+}
+
+// writeFrame пишет кадр клиента; mask == false — нарушение протокола
+func (c *testClient) writeFrame(fin bool, op byte, payload []byte, mask bool) {
+	c.t.Helper()
+	b0 := op
+	if fin {
+		b0 |= 0x80
+	}
+	frame := []byte{b0}
+
+	var maskBit byte
+	if mask {
+		maskBit = 0x80
+	}
+	n := len(payload)
+	switch {
+	case n <= 125:
+		frame = append(frame, maskBit|byte(n))
+	case n <= 0xFFFF:
+		frame = append(frame, maskBit|126)
+		frame = binary.BigEndian.AppendUint16(frame, uint16(n))
+	default:
+		frame = append(frame, maskBit|127)
+		frame = binary.BigEndian.AppendUint64(frame, uint64(n))
+	}
+
+	data := append([]byte(nil), payload...)
+	if mask {
+		key := [4]byte{0x12, 0x34, 0x56, 0x78}
+		frame = append(frame, key[:]...)
+		for i := range data {
+			data[i] ^= key[i%4]
+		}
+	}
+
+	if _, err := c.conn.Write(append(frame, data...)); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *testClient) send(op byte, payload string) {
+	c.writeFrame(true, op, []byte(payload), true)
+}
+
+// readFrame читает кадр сервера и проверяет, что он не замаскирован
+func (c *testClient) readFrame() (fin bool, op byte, payload []byte) {
+	c.t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(c.br, head[:]); err != nil {
+		c.t.Fatal(err)
+	}
+	if head[1]&0x80 != 0 {
+		c.t.Fatal("server frame is masked")
+	}
+	if head[0]&0x70 != 0 {
+		c.t.Fatal("server frame has reserved bits set")
+	}
+
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			c.t.Fatal(err)
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(c.br, ext[:]); err != nil {
+			c.t.Fatal(err)
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	payload = make([]byte, length)
+	if _, err := io.ReadFull(c.br, payload); err != nil {
+		c.t.Fatal(err)
+	}
+	return head[0]&0x80 != 0, head[0] & 0x0F, payload
+}
+
+// expectClose ждёт кадр закрытия с кодом code
+func (c *testClient) expectClose(code int) {
+	c.t.Helper()
+	_, op, payload := c.readFrame()
+	if op != opClose {
+		c.t.Fatalf("opcode = %#x, want close", op)
+	}
+	if len(payload) < 2 {
+		c.t.Fatalf("close payload too short: %v", payload)
+	}
+	if got := int(binary.BigEndian.Uint16(payload)); got != code {
+		c.t.Fatalf("close code = %d, want %d (%s)", got, code, payload[2:])
+	}
+}
+
+// echo отправляет обратно каждое сообщение, ошибку чтения кладёт в errs
+func echo(errs chan<- error) func(c *Conn) {
+	return func(c *Conn) {
+		for {
+			_, msg, err := c.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err := c.WriteText(msg); err != nil {
+				errs <- err
+				return
+			}
+		}
+	}
+}
+
+func waitErr(t *testing.T, errs <-chan error) error {
+	t.Helper()
+	select {
+	case err := <-errs:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not stop reading")
+		return nil
+	}
+}
+
+func TestUpgradeOrigin(t *testing.T) {
+	u := &Upgrader{AllowedOrigins: []string{"https://shop.example.com/"}}
+	srv := testServer(t, u, func(c *Conn) { c.Close(CloseNormal, "") })
+	host := srv.Listener.Addr().String()
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"", http.StatusSwitchingProtocols}, // не браузер
+		{"http://" + host, http.StatusSwitchingProtocols},
+		{"https://shop.example.com", http.StatusSwitchingProtocols},
+		{"HTTPS://SHOP.EXAMPLE.COM", http.StatusSwitchingProtocols},
+		{"https://evil.example.com", http.StatusForbidden},
+		{"https://shop.example.com.evil.com", http.StatusForbidden},
+		{"null", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if _, code := handshake(t, srv, tt.origin); code != tt.want {
+			t.Errorf("origin %q: status = %d, want %d", tt.origin, code, tt.want)
+		}
+	}
+}
+
+func TestUpgradeRejectsBadHandshake(t *testing.T) {
+	srv := testServer(t, &Upgrader{}, func(c *Conn) { c.Close(CloseNormal, "") })
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   int
+	}{
+		{"no upgrade", http.Header{"Sec-Websocket-Version": {"13"}, "Sec-Websocket-Key": {testKey}}, http.StatusUpgradeRequired},
+		{"old version", http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"8"}, "Sec-Websocket-Key": {testKey}}, http.StatusUpgradeRequired},
+		{"bad key", http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}, "Sec-Websocket-Version": {"13"}, "Sec-Websocket-Key": {"short"}}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+		req.Header = tt.header
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, resp.StatusCode, tt.want)
+		}
+	}
+}
+
+func TestWriteTextLengthEncoding(t *testing.T) {
+	sizes := []int{0, 125, 126, 0xFFFF, 0x10000}
+	c := dial(t, func(c *Conn) {
+		for _, n := range sizes {
+			if err := c.WriteText(bytes.Repeat([]byte("x"), n)); err != nil {
+				return
+			}
+		}
+	})
+
+	for _, n := range sizes {
+		fin, op, payload := c.readFrame()
+		if !fin || op != OpText || len(payload) != n {
+			t.Errorf("frame for %d bytes: fin=%v op=%#x len=%d", n, fin, op, len(payload))
+		}
+	}
+}
+
+func TestReadMaskedMessage(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, echo(errs))
+
+	for _, msg := range []string{"", "hello", strings.Repeat("a", 126), strings.Repeat("b", 0x10000)} {
+		c.send(OpText, msg)
+		_, op, payload := c.readFrame()
+		if op != OpText || string(payload) != msg {
+			t.Errorf("echo of %d bytes: op=%#x len=%d", len(msg), op, len(payload))
+		}
+	}
+}
+
+func TestFragmentedMessageWithInterleavedPing(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, echo(errs))
+
+	c.writeFrame(false, OpText, []byte("Hel"), true)
+	// управляющий кадр посреди фрагментированного сообщения разрешён
+	c.writeFrame(true, opPing, []byte("p1"), true)
+	c.writeFrame(false, opContinuation, []byte("lo, "), true)
+	c.writeFrame(true, opContinuation, []byte("world"), true)
+
+	_, op, payload := c.readFrame()
+	if op != opPong || string(payload) != "p1" {
+		t.Fatalf("first frame: op=%#x payload=%q, want pong \"p1\"", op, payload)
+	}
+	_, op, payload = c.readFrame()
+	if op != OpText || string(payload) != "Hello, world" {
+		t.Fatalf("message: op=%#x payload=%q", op, payload)
+	}
+}
+
+func TestPingPong(t *testing.T) {
+	pongs := make(chan struct{}, 1)
+	errs := make(chan error, 1)
+	c := dial(t, func(conn *Conn) {
+		conn.SetPongHandler(func() { pongs <- struct{}{} })
+		if err := conn.Ping(); err != nil {
+			errs <- err
+			return
+		}
+		echo(errs)(conn)
+	})
+
+	_, op, payload := c.readFrame()
+	if op != opPing || len(payload) != 0 {
+		t.Fatalf("op=%#x payload=%q, want empty ping", op, payload)
+	}
+	c.send(opPong, "")
+	select {
+	case <-pongs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pong handler was not called")
+	}
+
+	c.send(opPing, "are you there")
+	_, op, payload = c.readFrame()
+	if op != opPong || string(payload) != "are you there" {
+		t.Fatalf("op=%#x payload=%q, want pong with ping payload", op, payload)
+	}
+}
+
+func TestClientClose(t *testing.T) {
+	errs := make(chan error, 1)
+	c := dial(t, echo(errs))
+
+	payload := binary.BigEndian.AppendUint16(nil, CloseGoingAway)
+	c.writeFrame(true, opClose, payload, true)
+
+	c.expectClose(CloseGoingAway)
+	if err := waitErr(t, errs); !errors.Is(err, ErrClosed) {
+		t.Errorf("ReadMessage err = %v, want ErrClosed", err)
+	}
+}
+
+func TestServerClose(t *testing.T) {
+	c := dial(t, func(conn *Conn) {
+		conn.Close(CloseNormal, "auction closed")
+		// повторный Close ничего не пишет
+		conn.Close(CloseGoingAway, "")
+		if err := conn.WriteText([]byte("late")); !errors.Is(err, net.ErrClosed) {
+			t.Errorf("write after close err = %v", err)
+		}
+	})
+
+	_, op, payload := c.readFrame()
+	if op != opClose || binary.BigEndian.Uint16(payload) != CloseNormal || string(payload[2:]) != "auction closed" {
+		t.Fatalf("op=%#x payload=%q", op, payload)
+	}
+	if _, err := c.br.ReadByte(); err != io.EOF {
+		t.Errorf("after close: err = %v, want EOF", err)
+	}
+}
+
+func TestProtocolViolations(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(c *testClient)
+		code  int
+	}{
+		{"unmasked frame", func(c *testClient) {
+			c.writeFrame(true, OpText, []byte("hi"), false)
+		}, CloseProtocolError},
+		{"reserved bits", func(c *testClient) {
+			if _, err := c.conn.Write([]byte{0x80 | 0x40 | OpText, 0x80, 0, 0, 0, 0}); err != nil {
+				t.Fatal(err)
+			}
+		}, CloseProtocolError},
+		{"unknown opcode", func(c *testClient) {
+			c.writeFrame(true, 0x3, nil, true)
+		}, CloseProtocolError},
+		{"continuation without start", func(c *testClient) {
+			c.writeFrame(true, opContinuation, []byte("x"), true)
+		}, CloseProtocolError},
+		{"new message inside fragmented one", func(c *testClient) {
+			c.writeFrame(false, OpText, []byte("a"), true)
+			c.writeFrame(true, OpText, []byte("b"), true)
+		}, CloseProtocolError},
+		{"fragmented control frame", func(c *testClient) {
+			c.writeFrame(false, opPing, nil, true)
+		}, CloseProtocolError},
+		{"control frame too long", func(c *testClient) {
+			c.writeFrame(true, opPing, bytes.Repeat([]byte("p"), maxControlPayload+1), true)
+		}, CloseProtocolError},
+		{"frame too big", func(c *testClient) {
+			c.writeFrame(true, OpText, bytes.Repeat([]byte("x"), maxMessageSize+1), true)
+		}, CloseMessageTooBig},
+		{"fragments too big together", func(c *testClient) {
+			c.writeFrame(false, OpText, bytes.Repeat([]byte("x"), maxMessageSize/2+1), true)
+			c.writeFrame(true, opContinuation, bytes.Repeat([]byte("x"), maxMessageSize/2+1), true)
+		}, CloseMessageTooBig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := make(chan error, 1)
+			c := dial(t, echo(errs))
+
+			tt.write(c)
+			c.expectClose(tt.code)
+			if err := waitErr(t, errs); err == nil || errors.Is(err, ErrClosed) {
+				t.Errorf("ReadMessage err = %v, want protocol error", err)
+			}
+		})
+	}
+}